@@ -0,0 +1,64 @@
+package main
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// --------------------- League Averages ---------------------
+
+// averagesCollector exposes per-competition means of a few player stats,
+// computed from the last stored scrape each time /metrics is read. Averaging
+// the per-player gauges in PromQL means touching every player series; this
+// is one series per competition.
+type averagesCollector struct {
+	s      *scraper
+	fields []averageField
+}
+
+// averageField is one averaged stat: its series and how to read it from a player.
+type averageField struct {
+	desc  *prometheus.Desc
+	value func(PlayerStat) *float64
+}
+
+func newAveragesCollector(s *scraper) *averagesCollector {
+	field := func(name, help string, value func(PlayerStat) *float64) averageField {
+		return averageField{
+			desc:  prometheus.NewDesc(name, help, []string{"season", "competition"}, nil),
+			value: value,
+		}
+	}
+	return &averagesCollector{s: s, fields: []averageField{
+		field("premier_league_avg_player_goals", "Mean goals per player in each competition", func(p PlayerStat) *float64 { return p.Goals }),
+		field("premier_league_avg_player_assists", "Mean assists per player in each competition", func(p PlayerStat) *float64 { return p.Assists }),
+		field("premier_league_avg_player_xg", "Mean expected goals (xG) per player in each competition, over players with an xG figure", func(p PlayerStat) *float64 { return p.XG }),
+		field("premier_league_avg_player_xa", "Mean expected assists (xA) per player in each competition, over players with an xA figure", func(p PlayerStat) *float64 { return p.XA }),
+	}}
+}
+
+func (c *averagesCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, f := range c.fields {
+		ch <- f.desc
+	}
+}
+
+// Collect averages over the players that have a value for each stat; a
+// competition with none gets no series for it rather than a 0.
+func (c *averagesCollector) Collect(ch chan<- prometheus.Metric) {
+	c.s.snapshotMu.RLock()
+	defer c.s.snapshotMu.RUnlock()
+	for slug, stats := range c.s.snapshot {
+		for _, f := range c.fields {
+			sum, n := 0.0, 0
+			for _, p := range stats.Players {
+				if v := f.value(p); v != nil {
+					sum += *v
+					n++
+				}
+			}
+			if n > 0 {
+				ch <- prometheus.MustNewConstMetric(f.desc, prometheus.GaugeValue, sum/float64(n), c.s.season, slug)
+			}
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// --------------------- Disk Cache ---------------------
+
+// diskCache stores raw fetched pages on disk so restarts and offline development
+// don't have to hit FBref again. A nil *diskCache is a valid, disabled cache.
+type diskCache struct {
+	dir string
+	ttl time.Duration
+}
+
+// cacheMeta is the sidecar written next to each cached page.
+type cacheMeta struct {
+	URL       string    `json:"url"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+func newDiskCache(dir string, ttl time.Duration) (*diskCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &diskCache{dir: dir, ttl: ttl}, nil
+}
+
+// paths returns the body and sidecar paths for url.
+func (c *diskCache) paths(url string) (body, meta string) {
+	sum := sha1.Sum([]byte(url))
+	base := filepath.Join(c.dir, hex.EncodeToString(sum[:]))
+	return base + ".html", base + ".json"
+}
+
+// get returns the cached body for url if it was fetched within the TTL.
+func (c *diskCache) get(url string) ([]byte, bool) {
+	if c == nil {
+		return nil, false
+	}
+	bodyPath, metaPath := c.paths(url)
+	raw, err := os.ReadFile(metaPath)
+	if err != nil {
+		return nil, false
+	}
+	var meta cacheMeta
+	if err := json.Unmarshal(raw, &meta); err != nil || time.Since(meta.FetchedAt) > c.ttl {
+		return nil, false
+	}
+	body, err := os.ReadFile(bodyPath)
+	if err != nil {
+		return nil, false
+	}
+	return body, true
+}
+
+// put stores body for url, stamped with the current time.
+func (c *diskCache) put(url string, body []byte) error {
+	if c == nil {
+		return nil
+	}
+	bodyPath, metaPath := c.paths(url)
+	if err := os.WriteFile(bodyPath, body, 0o644); err != nil {
+		return err
+	}
+	meta, err := json.Marshal(cacheMeta{URL: url, FetchedAt: time.Now()})
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(metaPath, meta, 0o644)
+}
@@ -0,0 +1,334 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// --------------------- Config ---------------------
+
+// Config is the exporter's effective configuration: every flag after its
+// environment fallback and defaults have been applied.
+type Config struct {
+	ScrapeInterval     time.Duration `json:"scrape_interval"`
+	ScrapeTimeout      time.Duration `json:"scrape_timeout"`
+	StartupJitter      time.Duration `json:"startup_jitter"`
+	ListenAddress      string        `json:"listen_address"`
+	MetricsPath        string        `json:"metrics_path"`
+	SourceURL          string        `json:"source_url"`
+	Season             string        `json:"season"`
+	Source             string        `json:"source"`
+	Competitions       string        `json:"competitions"`
+	MaxRetries         int           `json:"max_retries"`
+	HTTPTimeout        time.Duration `json:"http_timeout"`
+	Concurrency        int           `json:"concurrency"`
+	RequestsPerMinute  int           `json:"requests_per_minute"`
+	UserAgents         string        `json:"user_agents"`
+	UserAgentsFile     string        `json:"user_agents_file"`
+	ProxyURL           string        `json:"proxy_url"`
+	Headers            []string      `json:"headers"`
+	BasicAuth          string        `json:"basic_auth"`
+	CAFile             string        `json:"ca_file"`
+	InsecureSkipVerify bool          `json:"insecure_skip_verify"`
+	HTMLFile           string        `json:"html_file"`
+	CacheDir           string        `json:"cache_dir"`
+	CacheTTL           time.Duration `json:"cache_ttl"`
+	TeamAliasesFile    string        `json:"team_aliases_file"`
+	TeamShortNamesFile string        `json:"team_short_names_file"`
+	SelectorsFile      string        `json:"selectors_file"`
+	SnapshotFile       string        `json:"snapshot_file"`
+	MaxCommentBytes    int           `json:"max_comment_bytes"`
+	MaxRowsPerTable    int           `json:"max_rows_per_table"`
+	MinTeams           int           `json:"min_teams"`
+	MaxTeams           int           `json:"max_teams"`
+	ScrapeFixtures     bool          `json:"scrape_fixtures"`
+	NormalizeLabels    bool          `json:"normalize_labels"`
+	PushgatewayURL     string        `json:"pushgateway_url"`
+	PushOnly           bool          `json:"push_only"`
+	DryRun             bool          `json:"dry_run"`
+	Textfile           string        `json:"textfile"`
+	LogFormat          string        `json:"log_format"`
+	Collectors         string        `json:"collectors"`
+	DisableGoMetrics   bool          `json:"disable_go_metrics"`
+
+	// Resolved from the fields above by loadConfig.
+	proxy        *url.URL
+	headers      http.Header
+	auth         *url.Userinfo
+	groups       metricGroups
+	competitions []competition
+}
+
+// loadConfig resolves the parsed flags into a Config, filling in defaults and
+// rejecting values the exporter can't run with.
+func loadConfig() (Config, error) {
+	cfg := Config{
+		ScrapeInterval:     *scrapeInterval,
+		ScrapeTimeout:      *scrapeTimeout,
+		StartupJitter:      *startupJitter,
+		ListenAddress:      *listenAddress,
+		MetricsPath:        *metricsPath,
+		SourceURL:          *sourceURL,
+		Season:             *season,
+		Source:             *source,
+		Competitions:       *competitions,
+		MaxRetries:         *maxRetries,
+		HTTPTimeout:        *httpTimeout,
+		Concurrency:        *concurrency,
+		RequestsPerMinute:  *requestsPerMin,
+		UserAgents:         *userAgents,
+		UserAgentsFile:     *userAgentsFile,
+		ProxyURL:           *proxyURL,
+		Headers:            *extraHeaders,
+		BasicAuth:          *basicAuth,
+		CAFile:             *caFile,
+		InsecureSkipVerify: *insecureTLS,
+		HTMLFile:           *htmlFile,
+		CacheDir:           *cacheDir,
+		CacheTTL:           *cacheTTL,
+		TeamAliasesFile:    *teamAliasFile,
+		TeamShortNamesFile: *shortNamesFile,
+		SelectorsFile:      *selectorsFile,
+		SnapshotFile:       *snapshotPath,
+		MaxCommentBytes:    *maxCommentSize,
+		MaxRowsPerTable:    *maxRowsPerTable,
+		MinTeams:           *minTeams,
+		MaxTeams:           *maxTeams,
+		ScrapeFixtures:     *scrapeFixtures,
+		NormalizeLabels:    *normalizeNames,
+		PushgatewayURL:     *pushgatewayURL,
+		PushOnly:           *pushOnly,
+		DryRun:             *dryRun,
+		Textfile:           *textfilePath,
+		LogFormat:          *logFormat,
+		Collectors:         *collectorsFlag,
+		DisableGoMetrics:   *disableGoMetrics,
+	}
+
+	switch cfg.LogFormat {
+	case "text", "json":
+	default:
+		return cfg, fmt.Errorf("invalid -log-format %q (expected text or json)", cfg.LogFormat)
+	}
+	if cfg.ScrapeInterval < minScrapeInterval {
+		log.Printf("[WARN] Scrape interval %s is below the minimum, using %s", cfg.ScrapeInterval, minScrapeInterval)
+		cfg.ScrapeInterval = minScrapeInterval
+	}
+	if cfg.MaxRetries < 0 {
+		return cfg, fmt.Errorf("-max-retries must not be negative, got %d", cfg.MaxRetries)
+	}
+	// 0 means fail fast: one attempt, like 1.
+	cfg.MaxRetries = max(cfg.MaxRetries, 1)
+	if cfg.ScrapeTimeout <= 0 {
+		cfg.ScrapeTimeout = cfg.ScrapeInterval
+	}
+	if cfg.StartupJitter < 0 {
+		return cfg, fmt.Errorf("-startup-jitter must not be negative, got %s", cfg.StartupJitter)
+	}
+	if cfg.HTTPTimeout <= 0 {
+		return cfg, fmt.Errorf("-http-timeout must be positive, got %s", cfg.HTTPTimeout)
+	}
+	if cfg.HTTPTimeout > cfg.ScrapeTimeout {
+		log.Printf("[WARN] -http-timeout %s exceeds the scrape deadline %s; requests will be cut off by the deadline", cfg.HTTPTimeout, cfg.ScrapeTimeout)
+	}
+	if cfg.MinTeams < 0 || cfg.MaxTeams < cfg.MinTeams {
+		return cfg, fmt.Errorf("invalid team count bounds -min-teams %d -max-teams %d", cfg.MinTeams, cfg.MaxTeams)
+	}
+	if cfg.Concurrency < 1 {
+		return cfg, fmt.Errorf("-concurrency must be at least 1, got %d", cfg.Concurrency)
+	}
+	if cfg.MaxRowsPerTable < 0 {
+		return cfg, fmt.Errorf("-max-rows-per-table must not be negative, got %d", cfg.MaxRowsPerTable)
+	}
+	if cfg.RequestsPerMinute < 0 {
+		return cfg, fmt.Errorf("-requests-per-minute must not be negative, got %d", cfg.RequestsPerMinute)
+	}
+	if cfg.Season != "" && !seasonPattern.MatchString(cfg.Season) {
+		return cfg, fmt.Errorf("invalid season %q (expected e.g. 2022-2023)", cfg.Season)
+	}
+	if cfg.HTMLFile != "" && cfg.ScrapeFixtures {
+		return cfg, errors.New("-html-file holds a single stats page and can't be combined with -scrape-fixtures")
+	}
+	if cfg.PushOnly && cfg.PushgatewayURL == "" {
+		return cfg, errors.New("-push-only requires -pushgateway-url")
+	}
+	if cfg.Textfile != "" && (cfg.DryRun || cfg.PushOnly) {
+		return cfg, errors.New("-textfile can't be combined with -dry-run or -push-only")
+	}
+	if !cfg.PushOnly && !cfg.DryRun && cfg.Textfile == "" {
+		if _, _, err := net.SplitHostPort(cfg.ListenAddress); err != nil {
+			return cfg, fmt.Errorf("invalid listen address %q (expected host:port or :port): %v", cfg.ListenAddress, err)
+		}
+	}
+	// Whitespace would make the mux read the path as a method and braces as
+	// a wildcard; it panics on the first and silently matches more on the second.
+	if !strings.HasPrefix(cfg.MetricsPath, "/") || strings.ContainsAny(cfg.MetricsPath, " \t\r\n{}") {
+		return cfg, fmt.Errorf("invalid -metrics-path %q (must start with / and contain no spaces or braces)", cfg.MetricsPath)
+	}
+	if slices.Contains(reservedPaths, cfg.MetricsPath) {
+		return cfg, fmt.Errorf("-metrics-path %s is already used by the exporter", cfg.MetricsPath)
+	}
+	groups, err := parseMetricGroups(cfg.Collectors)
+	if err != nil {
+		return cfg, fmt.Errorf("invalid -collectors: %w", err)
+	}
+	cfg.groups = groups
+	if cfg.ProxyURL != "" {
+		proxy, err := url.Parse(cfg.ProxyURL)
+		if err != nil || proxy.Host == "" {
+			return cfg, fmt.Errorf("invalid -proxy-url %q", redactURL(cfg.ProxyURL))
+		}
+		cfg.proxy = proxy
+	}
+	// Header values and the password may be secrets, so errors only ever
+	// quote the header name or the user.
+	for i, h := range cfg.Headers {
+		key, value, ok := strings.Cut(h, ":")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" || strings.ContainsAny(key, " \t") {
+			return cfg, fmt.Errorf("invalid -header #%d (expected \"Key: Value\")", i+1)
+		}
+		if cfg.headers == nil {
+			cfg.headers = http.Header{}
+		}
+		cfg.headers.Add(key, strings.TrimSpace(value))
+	}
+	if cfg.BasicAuth != "" {
+		user, pass, ok := strings.Cut(cfg.BasicAuth, ":")
+		if !ok || user == "" {
+			return cfg, errors.New("invalid -basic-auth (expected user:pass)")
+		}
+		cfg.auth = url.UserPassword(user, pass)
+	}
+
+	switch cfg.Source {
+	case "league":
+	case "big5":
+		if cfg.Competitions != "" || cfg.ScrapeFixtures {
+			return cfg, errors.New("-source big5 can't be combined with -competitions or -scrape-fixtures")
+		}
+	default:
+		return cfg, fmt.Errorf("invalid -source %q (expected league or big5)", cfg.Source)
+	}
+
+	if cfg.Source == "big5" {
+		cfg.competitions = []competition{{ID: "Big5", Slug: "Big-5-European-Leagues", URL: big5URL(cfg.Season), PlayersOnly: true}}
+	} else if cfg.Competitions != "" {
+		comps, err := parseCompetitions(cfg.Competitions, cfg.Season)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid -competitions: %w", err)
+		}
+		cfg.competitions = comps
+	} else {
+		page := cfg.SourceURL
+		if cfg.Season != "" {
+			page = buildURL("9", "Premier-League", cfg.Season)
+		}
+		cfg.competitions = []competition{{ID: "9", Slug: "Premier-League", URL: page, ScheduleURL: scheduleURL("9", "Premier-League", cfg.Season)}}
+	}
+	return cfg, nil
+}
+
+// reservedPaths are served by the exporter itself and can't be the metrics path.
+var reservedPaths = []string{"/", "/healthz", "/readyz", "/scrape", "/stats.json", "/config"}
+
+// metricGroups are the groups of metrics -collectors can turn on.
+type metricGroups struct {
+	teams, players, goalkeepers bool
+}
+
+// parseMetricGroups parses a -collectors list like "teams,players".
+func parseMetricGroups(spec string) (metricGroups, error) {
+	var g metricGroups
+	for _, name := range strings.Split(spec, ",") {
+		switch strings.TrimSpace(name) {
+		case "teams":
+			g.teams = true
+		case "players":
+			g.players = true
+		case "goalkeepers":
+			g.goalkeepers = true
+		case "":
+		default:
+			return g, fmt.Errorf("unknown group %q (expected teams, players or goalkeepers)", strings.TrimSpace(name))
+		}
+	}
+	if g == (metricGroups{}) {
+		return g, errors.New("no metric groups selected")
+	}
+	return g, nil
+}
+
+// MarshalJSON writes durations as "1h0m0s" rather than nanoseconds.
+func (c Config) MarshalJSON() ([]byte, error) {
+	type plain Config
+	return json.Marshal(struct {
+		plain
+		ScrapeInterval string `json:"scrape_interval"`
+		ScrapeTimeout  string `json:"scrape_timeout"`
+		StartupJitter  string `json:"startup_jitter"`
+		HTTPTimeout    string `json:"http_timeout"`
+		CacheTTL       string `json:"cache_ttl"`
+	}{
+		plain:          plain(c),
+		ScrapeInterval: c.ScrapeInterval.String(),
+		ScrapeTimeout:  c.ScrapeTimeout.String(),
+		StartupJitter:  c.StartupJitter.String(),
+		HTTPTimeout:    c.HTTPTimeout.String(),
+		CacheTTL:       c.CacheTTL.String(),
+	})
+}
+
+// redacted returns a copy of c that is safe to show: passwords embedded in
+// the source, proxy and Pushgateway URLs are masked, as are the -basic-auth
+// password and every -header value.
+func (c Config) redacted() Config {
+	c.SourceURL = redactURL(c.SourceURL)
+	c.ProxyURL = redactURL(c.ProxyURL)
+	c.PushgatewayURL = redactURL(c.PushgatewayURL)
+	if c.BasicAuth != "" {
+		user, _, _ := strings.Cut(c.BasicAuth, ":")
+		c.BasicAuth = user + ":<redacted>"
+	}
+	headers := make([]string, len(c.Headers))
+	for i, h := range c.Headers {
+		key, _, _ := strings.Cut(h, ":")
+		headers[i] = strings.TrimSpace(key) + ": <redacted>"
+	}
+	c.Headers = headers
+	return c
+}
+
+// redactURL masks the password in raw, if it has one. Unparseable values are
+// dropped entirely since there's no telling what part of them is secret.
+func redactURL(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "<redacted>"
+	}
+	return u.Redacted()
+}
+
+// configHandler serves the effective configuration (GET /config) as JSON.
+func configHandler(cfg Config) http.HandlerFunc {
+	body, err := json.Marshal(cfg.redacted())
+	return func(w http.ResponseWriter, _ *http.Request) {
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(append(body, '\n'))
+	}
+}
@@ -0,0 +1,435 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/time/rate"
+)
+
+// --------------------- HTML Fetching ---------------------
+
+// Fetcher retrieves and parses a page. It is the seam tests use to feed fixture HTML.
+type Fetcher interface {
+	Fetch(ctx context.Context, url string) (*goquery.Document, error)
+}
+
+// fileFetcher serves a pre-downloaded page for every URL, for air-gapped use
+// and for reproducing parser bugs from a saved page. The file is re-read on
+// each fetch so it can be swapped between scrapes.
+type fileFetcher struct {
+	path string
+}
+
+func (f fileFetcher) Fetch(_ context.Context, _ string) (*goquery.Document, error) {
+	file, err := os.Open(f.path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return goquery.NewDocumentFromReader(file)
+}
+
+// httpFetcher fetches pages from FBref over HTTP, retrying transient failures.
+type httpFetcher struct {
+	client      *http.Client
+	maxAttempts int
+	userAgents  []string
+	nextUA      atomic.Uint64
+	headers     http.Header
+	auth        *url.Userinfo
+	cache       *diskCache
+	m           *metrics
+
+	// limiter spaces out requests to FBref; nil means no limit. It is shared
+	// by every concurrent fetch.
+	limiter *rate.Limiter
+
+	mu    sync.Mutex
+	pages map[string]pageState
+}
+
+// pageState remembers the caching validators and parsed document of the last
+// 200 response for a URL, so unchanged pages can be revalidated with a
+// conditional GET instead of downloaded and parsed again.
+type pageState struct {
+	etag         string
+	lastModified string
+	doc          *goquery.Document
+}
+
+// response is the outcome of a successful download.
+type response struct {
+	body         []byte
+	etag         string
+	lastModified string
+	notModified  bool
+}
+
+// defaultUserAgents is a small pool of current desktop browsers. Rotating through
+// them makes the exporter a less obvious fingerprint than a single static string.
+var defaultUserAgents = []string{
+	"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36",
+	"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Safari/605.1.15",
+	"Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:125.0) Gecko/20100101 Firefox/125.0",
+	"Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36",
+	"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36 Edg/124.0.0.0",
+}
+
+// newHTTPFetcher builds a fetcher from cfg. -http-timeout applies to each
+// attempt on its own, not to the retries together; the caller's context bounds
+// those. Requests go through -proxy-url when it is set and otherwise honour
+// HTTP_PROXY, HTTPS_PROXY and NO_PROXY from the environment.
+func newHTTPFetcher(cfg Config, userAgents []string, tlsConfig *tls.Config, cache *diskCache, m *metrics) *httpFetcher {
+	if len(userAgents) == 0 {
+		userAgents = defaultUserAgents
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+	if cfg.proxy != nil {
+		transport.Proxy = http.ProxyURL(cfg.proxy)
+	}
+	if tlsConfig != nil {
+		transport.TLSClientConfig = tlsConfig
+	}
+	var limiter *rate.Limiter
+	if cfg.RequestsPerMinute > 0 {
+		// A burst of one: the first request goes straight out and the rest
+		// are spread evenly over the minute.
+		limiter = rate.NewLimiter(rate.Limit(float64(cfg.RequestsPerMinute)/60), 1)
+	}
+	return &httpFetcher{
+		client:      &http.Client{Timeout: cfg.HTTPTimeout, Transport: transport},
+		maxAttempts: cfg.MaxRetries,
+		userAgents:  userAgents,
+		headers:     cfg.headers,
+		auth:        cfg.auth,
+		cache:       cache,
+		m:           m,
+		limiter:     limiter,
+		pages:       map[string]pageState{},
+	}
+}
+
+// newTLSConfig builds the client TLS settings for -ca-file and
+// -insecure-skip-verify, or returns nil to keep Go's defaults.
+func newTLSConfig(caFile string, insecure bool) (*tls.Config, error) {
+	if caFile == "" && !insecure {
+		return nil, nil
+	}
+	cfg := &tls.Config{InsecureSkipVerify: insecure}
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading CA file: %w", err)
+		}
+		// Extend rather than replace the system roots, so FBref itself still
+		// verifies when the gateway is bypassed.
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New("no PEM certificates found in CA file")
+		}
+		cfg.RootCAs = pool
+	}
+	return cfg, nil
+}
+
+// userAgent returns the next User-Agent from the pool, round-robin.
+func (f *httpFetcher) userAgent() string {
+	n := f.nextUA.Add(1) - 1
+	return f.userAgents[n%uint64(len(f.userAgents))]
+}
+
+// Fetch downloads and parses url. A page revalidated with a 304 comes back as
+// the same *goquery.Document as last time, which lets the scraper reuse what
+// it parsed from it.
+func (f *httpFetcher) Fetch(ctx context.Context, url string) (*goquery.Document, error) {
+	if body, ok := f.cache.get(url); ok {
+		log.Printf("[INFO] Cache hit for %s", redactURL(url))
+		return goquery.NewDocumentFromReader(bytes.NewReader(body))
+	}
+	if f.cache != nil {
+		log.Printf("[INFO] Cache miss for %s", redactURL(url))
+	}
+
+	f.mu.Lock()
+	prev, havePrev := f.pages[url]
+	f.mu.Unlock()
+
+	resp, err := f.download(ctx, url, prev)
+	if err != nil {
+		return nil, err
+	}
+	if resp.notModified {
+		if !havePrev {
+			return nil, fmt.Errorf("unexpected 304 Not Modified for unconditional request")
+		}
+		log.Printf("[INFO] %s not modified, reusing last document", redactURL(url))
+		f.m.notModifiedTotal.Inc()
+		return prev.doc, nil
+	}
+	if err := f.cache.put(url, resp.body); err != nil {
+		log.Printf("[WARN] Failed to cache %s: %v", redactURL(url), err)
+	}
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(resp.body))
+	if err != nil {
+		return nil, err
+	}
+	if resp.etag != "" || resp.lastModified != "" {
+		f.mu.Lock()
+		f.pages[url] = pageState{etag: resp.etag, lastModified: resp.lastModified, doc: doc}
+		f.mu.Unlock()
+	}
+	return doc, nil
+}
+
+// download GETs url and returns the raw body, retrying with backoff. When prev
+// carries validators the request is conditional and a 304 is reported as
+// notModified with no body.
+func (f *httpFetcher) download(ctx context.Context, url string, prev pageState) (*response, error) {
+	// lastErr is why the latest attempt failed, reported once they run out.
+	var lastErr error
+	for attempt := 1; attempt <= f.maxAttempts; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+		if err != nil {
+			return nil, fmt.Errorf("building request: %w", err)
+		}
+		req.Header.Set("User-Agent", f.userAgent())
+		req.Header.Set("Accept-Language", "en-US,en;q=0.9")
+		req.Header.Set("Referer", "https://fbref.com/")
+		// Setting this ourselves turns off the transport's transparent gzip
+		// handling, so readBody decodes the response.
+		req.Header.Set("Accept-Encoding", "gzip, deflate")
+		// -header comes last so it can override any of the above.
+		for key, values := range f.headers {
+			req.Header[key] = slices.Clone(values)
+		}
+		if f.auth != nil {
+			pass, _ := f.auth.Password()
+			req.SetBasicAuth(f.auth.Username(), pass)
+		}
+		if prev.etag != "" {
+			req.Header.Set("If-None-Match", prev.etag)
+		}
+		if prev.lastModified != "" {
+			req.Header.Set("If-Modified-Since", prev.lastModified)
+		}
+		if err := f.wait(ctx, url); err != nil {
+			return nil, err
+		}
+		f.m.fetchAttempts.Inc()
+		resp, err := f.client.Do(req)
+		if err != nil {
+			f.m.fetchStatus.WithLabelValues("error").Inc()
+		} else {
+			f.m.fetchStatus.WithLabelValues(strconv.Itoa(resp.StatusCode)).Inc()
+		}
+		if err == nil && resp.StatusCode == http.StatusNotModified {
+			resp.Body.Close()
+			return &response{notModified: true}, nil
+		}
+		if err == nil && resp.StatusCode == http.StatusTooManyRequests {
+			resp.Body.Close()
+			f.m.fetchFailures.Inc()
+			lastErr = fmt.Errorf("rate limited: %s", resp.Status)
+			wait, ok := retryAfter(resp.Header.Get("Retry-After"), time.Now())
+			if !ok {
+				wait = backoff(attempt)
+			}
+			log.Printf("[WARN] Attempt %d rate limited by FBref, waiting %s before retrying", attempt, wait)
+			if err := f.pause(ctx, attempt, wait); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err == nil && resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			err = fmt.Errorf("unexpected status %s", resp.Status)
+		}
+		if err != nil {
+			f.m.fetchFailures.Inc()
+			lastErr = err
+			log.Printf("[WARN] Attempt %d failed: %v. Retrying...", attempt, err)
+			if err := f.pause(ctx, attempt, backoff(attempt)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		body, err := readBody(resp)
+		resp.Body.Close()
+		if err != nil {
+			f.m.fetchFailures.Inc()
+			lastErr = fmt.Errorf("reading body: %w", err)
+			log.Printf("[WARN] Failed to read body on attempt %d: %v", attempt, err)
+			if err := f.pause(ctx, attempt, backoff(attempt)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if isChallengePage(body) {
+			f.m.fetchFailures.Inc()
+			f.m.challengePages.Inc()
+			lastErr = errChallengePage
+			log.Printf("[WARN] Attempt %d got a Cloudflare challenge page instead of %s. Retrying...", attempt, redactURL(url))
+			if err := f.pause(ctx, attempt, backoff(attempt)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		log.Printf("[INFO] Fetched %s: %d bytes decoded (Content-Encoding %q, Content-Length %d)", redactURL(url), len(body), resp.Header.Get("Content-Encoding"), resp.ContentLength)
+		return &response{
+			body:         body,
+			etag:         resp.Header.Get("ETag"),
+			lastModified: resp.Header.Get("Last-Modified"),
+		}, nil
+	}
+	return nil, fmt.Errorf("failed to fetch HTML after %d attempts: %w", f.maxAttempts, lastErr)
+}
+
+// pause sleeps for d before retrying after a failed attempt. There is no
+// retry after the last attempt, so it returns at once instead of sleeping
+// before giving up.
+func (f *httpFetcher) pause(ctx context.Context, attempt int, d time.Duration) error {
+	if attempt >= f.maxAttempts {
+		return nil
+	}
+	return sleepCtx(ctx, d)
+}
+
+// wait blocks until the rate limiter lets another request through to FBref.
+func (f *httpFetcher) wait(ctx context.Context, url string) error {
+	if f.limiter == nil {
+		return nil
+	}
+	r := f.limiter.Reserve()
+	d := r.Delay()
+	if d == 0 {
+		return nil
+	}
+	log.Printf("[INFO] Rate limit: delaying fetch of %s by %s", redactURL(url), d.Round(time.Millisecond))
+	if err := sleepCtx(ctx, d); err != nil {
+		r.Cancel()
+		return err
+	}
+	return nil
+}
+
+// challengeMarkers are strings found in Cloudflare's "checking your browser"
+// interstitial, which FBref sometimes serves with a 200 status.
+var challengeMarkers = [][]byte{
+	[]byte("cf-browser-verification"),
+	[]byte("<title>Just a moment...</title>"),
+	[]byte("challenges.cloudflare.com"),
+	[]byte("cf_chl_opt"),
+}
+
+// errChallengePage is the failure of an attempt that got a Cloudflare challenge.
+var errChallengePage = errors.New("got a Cloudflare challenge page")
+
+// isChallengePage reports whether body is a Cloudflare challenge rather than
+// an FBref page. Only the head of the body is searched: the markers sit near
+// the top, and a real stats page is megabytes long.
+func isChallengePage(body []byte) bool {
+	head := body[:min(len(body), 64<<10)]
+	for _, m := range challengeMarkers {
+		if bytes.Contains(head, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// readBody reads the response body, decompressing gzip and deflate encodings.
+// Anything else, including no Content-Encoding at all, is read as is.
+func readBody(resp *http.Response) ([]byte, error) {
+	var r io.Reader = resp.Body
+	switch strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding"))) {
+	case "gzip":
+		zr, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("opening gzip body: %w", err)
+		}
+		defer zr.Close()
+		r = zr
+	case "deflate":
+		zr, err := zlib.NewReader(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("opening deflate body: %w", err)
+		}
+		defer zr.Close()
+		r = zr
+	}
+	return io.ReadAll(r)
+}
+
+// sleepCtx waits for d, returning early with the context's error if it is cancelled first.
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// Retry backoff grows exponentially from backoffBase up to backoffCap.
+const (
+	backoffBase = time.Second
+	backoffCap  = 30 * time.Second
+)
+
+// backoff returns a "full jitter" delay for the given 1-based attempt: a random
+// duration between zero and the exponential ceiling, so replicas retrying the
+// same outage spread out instead of retrying in lockstep.
+func backoff(attempt int) time.Duration {
+	ceiling := backoffCap
+	if shift := attempt - 1; shift < 6 {
+		ceiling = min(backoffBase<<shift, backoffCap)
+	}
+	return time.Duration(rand.Int63n(int64(ceiling) + 1))
+}
+
+// maxRetryAfter caps how long a Retry-After header can stall the exporter.
+const maxRetryAfter = 120 * time.Second
+
+// retryAfter interprets a Retry-After header given either as delay seconds or as
+// an HTTP-date, capped at maxRetryAfter. It reports false when the header is
+// missing or malformed.
+func retryAfter(header string, now time.Time) (time.Duration, bool) {
+	if header == "" {
+		return 0, false
+	}
+	var wait time.Duration
+	if secs, err := strconv.Atoi(header); err == nil {
+		wait = time.Duration(secs) * time.Second
+	} else if t, err := http.ParseTime(header); err == nil {
+		wait = t.Sub(now)
+	} else {
+		return 0, false
+	}
+	return min(max(wait, 0), maxRetryAfter), true
+}
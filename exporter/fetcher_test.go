@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Once the attempts run out, the error says why the last one failed.
+func TestFetchReportsLastAttemptError(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		want    string
+		is      error
+	}{
+		{
+			name:    "status",
+			handler: func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusForbidden) },
+			want:    "403 Forbidden",
+		},
+		{
+			name:    "rate limited",
+			handler: func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusTooManyRequests) },
+			want:    "rate limited: 429 Too Many Requests",
+		},
+		{
+			name: "challenge page",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.Write([]byte("<html><head><title>Just a moment...</title></head></html>"))
+			},
+			is: errChallengePage,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(tt.handler)
+			defer srv.Close()
+			f := newHTTPFetcher(Config{MaxRetries: 1}, nil, nil, nil, newMetrics(prometheus.NewRegistry(), allGroups))
+
+			_, err := f.Fetch(context.Background(), srv.URL)
+			if err == nil {
+				t.Fatal("Fetch succeeded, want an error")
+			}
+			if tt.want != "" && !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got %q, want it to mention %q", err, tt.want)
+			}
+			if tt.is != nil && !errors.Is(err, tt.is) {
+				t.Errorf("got %q, want it to wrap %q", err, tt.is)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata" // Europe/London must resolve even in minimal images.
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// --------------------- Fixtures ---------------------
+
+// tableFixture attributes parse errors on the schedule page.
+const tableFixture = "fixture"
+
+// Schedule is what the exporter takes from a competition's "Scores & Fixtures" page.
+type Schedule struct {
+	// Attendance is the average recorded home attendance per team, over the
+	// matches played so far that have an attendance figure.
+	Attendance map[string]float64
+
+	// Upcoming lists the fixtures that have no score yet.
+	Upcoming []Fixture
+
+	// Results lists the matches played so far with their scores.
+	Results []Result
+
+	ParseErrors map[string]int
+}
+
+// Fixture is one scheduled, not yet played match.
+type Fixture struct {
+	Home    string     `json:"home"`
+	Away    string     `json:"away"`
+	Date    string     `json:"date"`
+	Kickoff *time.Time `json:"kickoff,omitempty"`
+}
+
+// Result is one played match and its final score.
+type Result struct {
+	Home      string  `json:"home"`
+	Away      string  `json:"away"`
+	Date      string  `json:"date"`
+	HomeGoals float64 `json:"home_goals"`
+	AwayGoals float64 `json:"away_goals"`
+}
+
+// penaltyScore matches the shoot-out tallies FBref puts either side of a cup
+// score, e.g. "(4) 1–1 (3)".
+var penaltyScore = regexp.MustCompile(`\(\d+\)`)
+
+// scoreDashReplacer turns the en and em dashes FBref separates scores with
+// into a plain hyphen.
+var scoreDashReplacer = strings.NewReplacer("\u2013", "-", "\u2014", "-")
+
+// parseScore reads a score cell like "2–1", ignoring any penalty tallies.
+func parseScore(raw string) (home, away float64, ok bool) {
+	raw = penaltyScore.ReplaceAllString(scoreDashReplacer.Replace(raw), "")
+	h, a, found := strings.Cut(raw, "-")
+	if !found {
+		return 0, 0, false
+	}
+	hg, err1 := strconv.Atoi(strings.TrimSpace(h))
+	ag, err2 := strconv.Atoi(strings.TrimSpace(a))
+	if err1 != nil || err2 != nil {
+		return 0, 0, false
+	}
+	return float64(hg), float64(ag), true
+}
+
+// fixtureLocation is the zone FBref's schedule lists kickoff times in: the
+// venue's local time, which for English competitions is UK time.
+var fixtureLocation = func() *time.Location {
+	loc, err := time.LoadLocation("Europe/London")
+	if err != nil {
+		panic(err) // unreachable with time/tzdata embedded
+	}
+	return loc
+}()
+
+// kickoffTime combines the schedule's date ("2024-08-17") and start time cells.
+// The time cell may carry the viewer's local time after the venue time, e.g.
+// "15:00 (16:00)", so only the leading HH:MM is used. It reports false for
+// fixtures without a confirmed kickoff time.
+func kickoffTime(date, clock string) (time.Time, bool) {
+	clock, _, _ = strings.Cut(strings.TrimSpace(clock), " ")
+	t, err := time.ParseInLocation("2006-01-02 15:04", date+" "+clock, fixtureLocation)
+	return t, err == nil
+}
+
+// parseSchedule reads the fixtures table of a schedule page, at most maxRows
+// rows of it. Spacer and repeated header rows have no home team and are
+// skipped; a score that doesn't parse counts as a fixture parse error.
+func parseSchedule(d *goquery.Document, maxRows int) *Schedule {
+	b := &statsBuilder{stats: Stats{ParseErrors: map[string]int{}}, maxRows: maxRows}
+	total, games := map[string]float64{}, map[string]int{}
+	var upcoming []Fixture
+	var results []Result
+	b.rows(d.Selection).Each(func(_ int, s *goquery.Selection) {
+		home := normalizeTeamName(cellText(s, "fixture_home_team"))
+		if home == "" {
+			return
+		}
+		away, date := normalizeTeamName(cellText(s, "fixture_away_team")), cellText(s, "fixture_date")
+		score := cellText(s, "fixture_score")
+		if score == "" {
+			f := Fixture{Home: home, Away: away, Date: date}
+			if t, ok := kickoffTime(f.Date, cellText(s, "fixture_start_time")); ok {
+				f.Kickoff = &t
+			}
+			upcoming = append(upcoming, f)
+			return
+		}
+		if hg, ag, ok := parseScore(score); ok {
+			results = append(results, Result{Home: home, Away: away, Date: date, HomeGoals: hg, AwayGoals: ag})
+		} else {
+			b.stats.ParseErrors[tableFixture]++
+		}
+		// Attendance is comma-separated ("52,203") and blank for unplayed or
+		// behind-closed-doors matches.
+		if v, ok := b.parse(tableFixture, cellText(s, "fixture_attendance")); ok {
+			total[home] += v
+			games[home]++
+		}
+	})
+
+	sched := &Schedule{Attendance: map[string]float64{}, Upcoming: upcoming, Results: results, ParseErrors: b.stats.ParseErrors}
+	for team, n := range games {
+		sched.Attendance[team] = total[team] / float64(n)
+	}
+	return sched
+}
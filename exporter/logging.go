@@ -0,0 +1,39 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+)
+
+// --------------------- Logging ---------------------
+
+// jsonLogWriter turns the exporter's "[LEVEL] message" log lines into one JSON
+// object per line for -log-format json, so log shippers don't need a parser.
+// It expects the standard logger to be configured without flags.
+type jsonLogWriter struct {
+	out io.Writer
+}
+
+func (w jsonLogWriter) Write(p []byte) (int, error) {
+	msg := strings.TrimRight(string(p), "\n")
+	level := "info"
+	if rest, ok := strings.CutPrefix(msg, "["); ok {
+		if lvl, text, ok := strings.Cut(rest, "] "); ok {
+			level, msg = strings.ToLower(lvl), text
+		}
+	}
+	line, err := json.Marshal(map[string]string{
+		"time":  time.Now().UTC().Format(time.RFC3339Nano),
+		"level": level,
+		"msg":   msg,
+	})
+	if err != nil {
+		return 0, err
+	}
+	if _, err := w.out.Write(append(line, '\n')); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -1,217 +1,1153 @@
 package main
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"html"
 	"log"
+	"maps"
+	"math/rand"
 	"net"
 	"net/http"
+	"os"
+	"os/signal"
 	"regexp"
+	"runtime"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
-// --------------------- Metrics Definitions ---------------------
+// --------------------- Configuration ---------------------
 
+// Build metadata, injected with -ldflags "-X main.version=... -X main.commit=...".
 var (
-	// Player-level metrics
-	topScorer = prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{Name: "premier_league_player_goals", Help: "Goals scored by each Premier League player"},
-		[]string{"player", "team"},
-	)
-	topAssists = prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{Name: "premier_league_player_assists", Help: "Assists made by each Premier League player"},
-		[]string{"player", "team"},
-	)
-	cleanSheets = prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{Name: "premier_league_goalkeeper_clean_sheets", Help: "Number of clean sheets by each goalkeeper"},
-		[]string{"player", "team"},
-	)
-
-	// Team-level metrics
-	teamPoints       = prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "premier_league_team_points", Help: "Current Premier League points per team"}, []string{"team"})
-	teamGoalsFor     = prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "premier_league_team_goals_for", Help: "Total goals scored per team"}, []string{"team"})
-	teamGoalsAgainst = prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "premier_league_team_goals_against", Help: "Total goals conceded per team"}, []string{"team"})
-	teamWins         = prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "premier_league_team_wins", Help: "Total wins per team"}, []string{"team"})
-	teamDraws        = prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "premier_league_team_draws", Help: "Total draws per team"}, []string{"team"})
-	teamLosses       = prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "premier_league_team_losses", Help: "Total losses per team"}, []string{"team"})
-
-	// Exporter health metrics
-	scrapeSuccess  = prometheus.NewGauge(prometheus.GaugeOpts{Name: "fbref_scrape_success", Help: "Whether the last scrape succeeded (1=success, 0=failure)"})
-	scrapeDuration = prometheus.NewGauge(prometheus.GaugeOpts{Name: "fbref_scrape_duration_seconds", Help: "Time taken for the last FBref scrape in seconds"})
+	version = "dev"
+	commit  = "unknown"
 )
 
-func init() {
-	prometheus.MustRegister(topScorer, topAssists, cleanSheets)
-	prometheus.MustRegister(teamPoints, teamGoalsFor, teamGoalsAgainst, teamWins, teamDraws, teamLosses)
-	prometheus.MustRegister(scrapeSuccess, scrapeDuration)
-}
-
-// --------------------- HTML Fetching ---------------------
-
-func fetchHTML(url string) (*goquery.Document, error) {
-	client := &http.Client{Timeout: 25 * time.Second}
-	for attempt := 1; attempt <= 3; attempt++ {
-		req, _ := http.NewRequest("GET", url, nil)
-		req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64)")
-		req.Header.Set("Accept-Language", "en-US,en;q=0.9")
-		req.Header.Set("Referer", "https://fbref.com/")
-		resp, err := client.Do(req)
-		if err != nil || resp.StatusCode != 200 {
-			if resp != nil {
-				resp.Body.Close()
-			}
-			log.Printf("[WARN] Attempt %d failed: %v. Retrying...", attempt, err)
-			time.Sleep(time.Duration(attempt*2) * time.Second)
-			continue
+// minScrapeInterval keeps the exporter from hammering FBref with a too-eager interval.
+const minScrapeInterval = time.Minute
+
+// defaultSourceURL is the current-season Premier League stats page.
+const defaultSourceURL = "https://fbref.com/en/comps/9/Premier-League-Stats"
+
+// defaultMaxRows is the -max-rows-per-table default. It has to clear the big-5
+// player page, the largest table FBref serves, at around 2,700 rows.
+const defaultMaxRows = 10000
+
+// currentSeason is the season label used when no -season is given.
+const currentSeason = "current"
+
+// seasonPattern matches FBref's season segment, e.g. "2022-2023".
+var seasonPattern = regexp.MustCompile(`^\d{4}-\d{4}$`)
+
+var (
+	scrapeInterval   = flag.Duration("scrape-interval", envDuration("SCRAPE_INTERVAL", time.Hour), "How often to scrape FBref (env SCRAPE_INTERVAL)")
+	metricsPath      = flag.String("metrics-path", envString("METRICS_PATH", "/metrics"), "Path to serve metrics on; / serves an index page linking to it (env METRICS_PATH)")
+	listenAddress    = flag.String("listen-address", envString("LISTEN_ADDRESS", ":2113"), "Address to serve metrics on (env LISTEN_ADDRESS)")
+	sourceURL        = flag.String("source-url", envString("FBREF_URL", defaultSourceURL), "FBref stats page to scrape (env FBREF_URL)")
+	season           = flag.String("season", envString("SEASON", ""), "Historical season to scrape, e.g. 2022-2023; overrides -source-url (env SEASON)")
+	maxRetries       = flag.Int("max-retries", envInt("MAX_RETRIES", 3), "Maximum fetch attempts per page; 0 or 1 makes a single attempt with no retry (env MAX_RETRIES)")
+	userAgents       = flag.String("user-agents", envString("USER_AGENTS", ""), "Comma-separated User-Agent strings to rotate through (env USER_AGENTS)")
+	userAgentsFile   = flag.String("user-agents-file", envString("USER_AGENTS_FILE", ""), "File with one User-Agent per line to rotate through (env USER_AGENTS_FILE)")
+	proxyURL         = flag.String("proxy-url", envString("PROXY_URL", ""), "Proxy for FBref requests; defaults to HTTP_PROXY/HTTPS_PROXY/NO_PROXY (env PROXY_URL)")
+	caFile           = flag.String("ca-file", envString("CA_FILE", ""), "PEM file of extra root CAs to trust when fetching, e.g. for a TLS-intercepting gateway (env CA_FILE)")
+	insecureTLS      = flag.Bool("insecure-skip-verify", false, "Skip TLS certificate verification when fetching; for debugging only")
+	htmlFile         = flag.String("html-file", envString("HTML_FILE", ""), "Parse this saved FBref page instead of fetching over HTTP (env HTML_FILE)")
+	cacheDir         = flag.String("cache-dir", envString("CACHE_DIR", ""), "Directory to cache fetched pages in; disabled when empty (env CACHE_DIR)")
+	cacheTTL         = flag.Duration("cache-ttl", envDuration("CACHE_TTL", time.Hour), "How long cached pages are served before refetching (env CACHE_TTL)")
+	shortNamesFile   = flag.String("team-short-names-file", envString("TEAM_SHORT_NAMES_FILE", ""), "JSON object of canonical team name to short_name label, overriding the built-in codes (env TEAM_SHORT_NAMES_FILE)")
+	snapshotPath     = flag.String("snapshot-file", envString("SNAPSHOT_FILE", ""), "File to save parsed stats to after each successful scrape and restore metrics from at startup (env SNAPSHOT_FILE)")
+	teamAliasFile    = flag.String("team-aliases-file", envString("TEAM_ALIASES_FILE", ""), "JSON object of extra team name aliases to canonical names (env TEAM_ALIASES_FILE)")
+	selectorsFile    = flag.String("selectors-file", envString("SELECTORS_FILE", ""), "JSON object overriding the FBref data-stat attribute read for each field, e.g. {\"player_goals\": \"goals\"} (env SELECTORS_FILE)")
+	maxCommentSize   = flag.Int("max-comment-bytes", envInt("MAX_COMMENT_BYTES", 4<<20), "Largest HTML comment to scan for hidden tables (env MAX_COMMENT_BYTES)")
+	maxRowsPerTable  = flag.Int("max-rows-per-table", envInt("MAX_ROWS_PER_TABLE", defaultMaxRows), "Most rows read from any one table; extra rows are ignored with a warning, 0 removes the limit (env MAX_ROWS_PER_TABLE)")
+	pushgatewayURL   = flag.String("pushgateway-url", envString("PUSHGATEWAY_URL", ""), "Pushgateway to push metrics to after each scrape (env PUSHGATEWAY_URL)")
+	textfilePath     = flag.String("textfile", envString("TEXTFILE", ""), "Scrape once, write the metrics in Prometheus text format to this file (- for stdout) for node_exporter's textfile collector, and exit; serves nothing (env TEXTFILE)")
+	dryRun           = flag.Bool("dry-run", false, "Scrape once, print a summary of what was parsed and exit 1 if the scrape failed; serves and pushes nothing")
+	logFormat        = flag.String("log-format", envString("LOG_FORMAT", "text"), "Log and -dry-run summary format: text or json (env LOG_FORMAT)")
+	pushOnly         = flag.Bool("push-only", false, "Only push to -pushgateway-url; don't serve HTTP")
+	source           = flag.String("source", envString("SOURCE", "league"), "Pages to scrape: league for per-competition pages, or big5 for FBref's combined top-5 European leagues player page (env SOURCE)")
+	competitions     = flag.String("competitions", envString("COMPETITIONS", ""), "Comma-separated id:slug list of competitions, e.g. 9:Premier-League,10:Championship; append :cup for one without a league table, e.g. 514:FA-Cup:cup; overrides -source-url (env COMPETITIONS)")
+	httpTimeout      = flag.Duration("http-timeout", envDuration("HTTP_TIMEOUT", 25*time.Second), "Timeout for a single HTTP attempt, including reading the body (env HTTP_TIMEOUT)")
+	startupJitter    = flag.Duration("startup-jitter", envDuration("STARTUP_JITTER", 0), "Wait a random time up to this long before the first scrape, so replicas started together don't hit FBref at once; 0 scrapes immediately (env STARTUP_JITTER)")
+	scrapeTimeout    = flag.Duration("scrape-timeout", envDuration("SCRAPE_TIMEOUT", 0), "Deadline for a whole scrape including retries; 0 means the scrape interval (env SCRAPE_TIMEOUT)")
+	minTeams         = flag.Int("min-teams", envInt("MIN_TEAMS", 20), "Fewest teams a competition's standings may have before the scrape is rejected (env MIN_TEAMS)")
+	maxTeams         = flag.Int("max-teams", envInt("MAX_TEAMS", 24), "Most teams a competition's standings may have before the scrape is rejected (env MAX_TEAMS)")
+	concurrency      = flag.Int("concurrency", envInt("CONCURRENCY", 3), "Maximum pages fetched in parallel (env CONCURRENCY)")
+	requestsPerMin   = flag.Int("requests-per-minute", envInt("REQUESTS_PER_MINUTE", 10), "Most requests sent to FBref per minute, across all concurrent fetches; 0 disables the limit (env REQUESTS_PER_MINUTE)")
+	collectorsFlag   = flag.String("collectors", envString("COLLECTORS", "teams,players,goalkeepers"), "Comma-separated metric groups to scrape and expose: teams, players, goalkeepers (env COLLECTORS)")
+	disableGoMetrics = flag.Bool("disable-go-metrics", false, "Omit the Go runtime (go_*) and process (process_*) metrics from /metrics and pushes")
+	normalizeNames   = flag.Bool("normalize-labels", false, "Unicode-normalize (NFC) and trim player and team names before using them as labels")
+	basicAuth        = flag.String("basic-auth", envString("BASIC_AUTH", ""), "user:pass sent as HTTP basic auth with every FBref request, e.g. for an authenticated mirror (env BASIC_AUTH)")
+	extraHeaders     = headerFlag("header", "Extra request header as \"Key: Value\", sent with every FBref request; repeatable")
+	scrapeFixtures   = flag.Bool("scrape-fixtures", false, "Also fetch each competition's fixtures page for attendance and upcoming fixtures (one extra request per competition)")
+)
+
+// loadUserAgents builds the User-Agent pool from -user-agents and -user-agents-file.
+// An empty result means the built-in pool is used.
+func loadUserAgents(list, file string) ([]string, error) {
+	var agents []string
+	for _, ua := range strings.Split(list, ",") {
+		if ua = strings.TrimSpace(ua); ua != "" {
+			agents = append(agents, ua)
 		}
-		doc, err := goquery.NewDocumentFromReader(resp.Body)
-		resp.Body.Close()
+	}
+	if file != "" {
+		data, err := os.ReadFile(file)
 		if err != nil {
-			log.Printf("[WARN] Failed to parse HTML on attempt %d: %v", attempt, err)
-			time.Sleep(time.Duration(attempt*2) * time.Second)
-			continue
+			return nil, fmt.Errorf("reading user agents file: %w", err)
+		}
+		for _, ua := range strings.Split(string(data), "\n") {
+			if ua = strings.TrimSpace(ua); ua != "" && !strings.HasPrefix(ua, "#") {
+				agents = append(agents, ua)
+			}
 		}
-		return doc, nil
 	}
-	return nil, fmt.Errorf("failed to fetch HTML after 3 attempts")
+	return agents, nil
+}
+
+// headerList collects the values of a repeatable -header flag.
+type headerList []string
+
+func (h *headerList) String() string { return strings.Join(*h, ", ") }
+
+func (h *headerList) Set(v string) error {
+	*h = append(*h, v)
+	return nil
+}
+
+// headerFlag defines a repeatable flag and returns where its values land.
+func headerFlag(name, usage string) *headerList {
+	h := new(headerList)
+	flag.Var(h, name, usage)
+	return h
+}
+
+// envString reads a string from the environment, falling back to def when unset.
+func envString(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+// envInt reads an integer from the environment, falling back to def when unset or invalid.
+func envInt(key string, def int) int {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		log.Printf("[WARN] Ignoring invalid %s=%q: %v", key, v, err)
+		return def
+	}
+	return n
+}
+
+// envDuration reads a duration from the environment, falling back to def when unset or invalid.
+func envDuration(key string, def time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		log.Printf("[WARN] Ignoring invalid %s=%q: %v", key, v, err)
+		return def
+	}
+	return d
 }
 
 // --------------------- Scraper Logic ---------------------
 
-func extractCommentTables(html string) []*goquery.Document {
-	re := regexp.MustCompile(`<!--([\s\S]*?)-->`)
-	matches := re.FindAllStringSubmatch(html, -1)
+// extractCommentTables returns the FBref stats tables hidden inside HTML
+// comments. Rather than a regex over the whole page it scans forward once,
+// stepping over <script> and <style> bodies so a "-->" inside JavaScript
+// can't end a comment early, and stopping at a truncated comment. Comments
+// larger than maxSize are skipped to bound memory on malformed pages.
+func extractCommentTables(html string, maxSize int) []*goquery.Document {
 	var docs []*goquery.Document
-	for _, m := range matches {
-		if strings.Contains(m[1], "<table") {
-			doc, err := goquery.NewDocumentFromReader(strings.NewReader(m[1]))
-			if err == nil {
+	for i := 0; i < len(html); {
+		next := strings.IndexByte(html[i:], '<')
+		if next < 0 {
+			break
+		}
+		i += next
+		rest := html[i:]
+		switch {
+		case strings.HasPrefix(rest, "<!--"):
+			end := strings.Index(rest[4:], "-->")
+			if end < 0 {
+				return docs
+			}
+			body := rest[4 : 4+end]
+			i += 4 + end + 3
+			if len(body) > maxSize {
+				log.Printf("[WARN] Skipping %d-byte HTML comment larger than %d bytes", len(body), maxSize)
+				continue
+			}
+			if !strings.Contains(body, "<table") || !strings.Contains(body, "stats_table") {
+				continue
+			}
+			if doc, err := goquery.NewDocumentFromReader(strings.NewReader(body)); err == nil {
 				docs = append(docs, doc)
 			}
+		case hasPrefixFold(rest, "<script"), hasPrefixFold(rest, "<style"):
+			tag := "</script"
+			if hasPrefixFold(rest, "<style") {
+				tag = "</style"
+			}
+			end := indexFold(rest, tag)
+			if end < 0 {
+				return docs
+			}
+			i += end + len(tag)
+		default:
+			i++
 		}
 	}
 	return docs
 }
 
-func scrapeFBref() {
+// dedupeTables returns the documents to parse: doc plus the comment documents,
+// with every table parsed once. FBref sometimes ships a table both live and
+// inside a comment; the live copy is removed from a clone of doc (the fetcher
+// may hand the same document out again), and a comment repeating tables from
+// an earlier one is dropped. Tables are matched by id, or by content when
+// they have none.
+func dedupeTables(doc *goquery.Document, comments []*goquery.Document) []*goquery.Document {
+	seen := map[string]bool{}
+	docs := []*goquery.Document{nil}
+	dropped := 0
+	for _, c := range comments {
+		tables := c.Find("table")
+		fresh := false
+		tables.Each(func(_ int, t *goquery.Selection) {
+			if key := tableKey(t); !seen[key] {
+				seen[key] = true
+				fresh = true
+			}
+		})
+		if !fresh && tables.Length() > 0 {
+			dropped += tables.Length()
+			continue
+		}
+		docs = append(docs, c)
+	}
+
+	doc = goquery.CloneDocument(doc)
+	doc.Find("table").Each(func(_ int, t *goquery.Selection) {
+		if seen[tableKey(t)] {
+			t.Remove()
+			dropped++
+		}
+	})
+	docs[0] = doc
+	if dropped > 0 {
+		log.Printf("[INFO] Skipping %d duplicate tables found both on the page and in its comments", dropped)
+	}
+	return docs
+}
+
+// tableKey identifies a table for dedupeTables.
+func tableKey(t *goquery.Selection) string {
+	if id, ok := t.Attr("id"); ok && id != "" {
+		return "id:" + id
+	}
+	html, _ := goquery.OuterHtml(t)
+	sum := sha256.Sum256([]byte(html))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func hasPrefixFold(s, prefix string) bool {
+	return len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix)
+}
+
+// indexFold is a case-insensitive strings.Index for ASCII needles.
+func indexFold(s, substr string) int {
+	for i := 0; i+len(substr) <= len(s); i++ {
+		if strings.EqualFold(s[i:i+len(substr)], substr) {
+			return i
+		}
+	}
+	return -1
+}
+
+// series records the label sets written to each gauge during one scrape of a
+// competition. Comparing it with the previous scrape tells us exactly which
+// series disappeared, so we can delete just those instead of calling Reset() on
+// everything up front. Resetting first left gaps (and NaNs in Grafana) whenever a
+// scrape failed part-way; now a failed fetch keeps the last values in place.
+type series map[*prometheus.GaugeVec]map[string][]string
+
+func (sr series) set(g *prometheus.GaugeVec, v float64, labels ...string) {
+	g.WithLabelValues(labels...).Set(v)
+	if sr[g] == nil {
+		sr[g] = map[string][]string{}
+	}
+	sr[g][strings.Join(labels, "\xff")] = labels
+}
+
+// setIf sets the labelled series only when the value was present in the scrape.
+func (sr series) setIf(g *prometheus.GaugeVec, v *float64, labels ...string) {
+	if v != nil {
+		sr.set(g, *v, labels...)
+	}
+}
+
+// deleteStale removes every series from prev that was not written again in sr.
+func (sr series) deleteStale(prev series) {
+	for g, keys := range prev {
+		for key, labels := range keys {
+			if _, ok := sr[g][key]; !ok {
+				g.DeleteLabelValues(labels...)
+			}
+		}
+	}
+}
+
+// competition identifies one FBref competition page to scrape.
+type competition struct {
+	ID   string
+	Slug string
+	URL  string
+
+	// ScheduleURL is the competition's "Scores & Fixtures" page.
+	ScheduleURL string
+
+	// PlayersOnly marks a page without standings, such as the big-5 player
+	// page or a cup's, so the team-count check is skipped.
+	PlayersOnly bool
+}
+
+// parseCompetitions parses a -competitions spec like "9:Premier-League,10:Championship".
+// A ":cup" suffix, as in "514:FA-Cup:cup", marks a competition without standings.
+func parseCompetitions(spec, season string) ([]competition, error) {
+	var comps []competition
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, slug, ok := strings.Cut(part, ":")
+		slug, kind, _ := strings.Cut(slug, ":")
+		if !ok || id == "" || slug == "" || (kind != "" && kind != "cup") {
+			return nil, fmt.Errorf("invalid competition %q (expected id:slug or id:slug:cup)", part)
+		}
+		comps = append(comps, competition{
+			ID:          id,
+			Slug:        slug,
+			URL:         buildURL(id, slug, season),
+			ScheduleURL: scheduleURL(id, slug, season),
+			PlayersOnly: kind == "cup",
+		})
+	}
+	if len(comps) == 0 {
+		return nil, errors.New("no competitions configured")
+	}
+	return comps, nil
+}
+
+// compPageURL builds the URL of a page under a competition. Past seasons get
+// a season segment after the id and the season as a prefix of the page name,
+// e.g. comps/9/2022-2023/2022-2023-Premier-League-Stats; the current season
+// (season empty) has neither.
+func compPageURL(id, season, dir, name string) string {
+	u := "https://fbref.com/en/comps/" + id + "/"
+	if season != "" {
+		u += season + "/"
+		name = season + "-" + name
+	}
+	return u + dir + name
+}
+
+// buildURL returns the stats page for a competition, for the current season
+// when season is empty.
+func buildURL(compID, compSlug, season string) string {
+	return compPageURL(compID, season, "", compSlug+"-Stats")
+}
+
+// big5URL returns FBref's combined player stats page for the top five
+// European leagues.
+func big5URL(season string) string {
+	return compPageURL("Big5", season, "stats/players/", "Big-5-European-Leagues-Stats")
+}
+
+// scheduleURL is buildURL for the competition's fixtures page.
+func scheduleURL(id, slug, season string) string {
+	return compPageURL(id, season, "schedule/", slug+"-Scores-and-Fixtures")
+}
+
+// scraper ties a Fetcher to the competitions it scrapes.
+type scraper struct {
+	fetcher      Fetcher
+	competitions []competition
+	season       string
+	interval     time.Duration
+
+	// timeout bounds a whole scrape. Each HTTP attempt has its own, shorter
+	// client timeout; whichever expires first ends that attempt, and once the
+	// scrape deadline passes no further retries are made.
+	timeout time.Duration
+
+	// pusher, when set, pushes metrics to a Pushgateway after every scrape.
+	pusher *gatewayPusher
+
+	// maxCommentBytes bounds the size of a commented-out table we will parse.
+	maxCommentBytes int
+
+	// maxRows bounds the rows read from each table.
+	maxRows int
+
+	// groups are the metric groups scraped (-collectors).
+	groups metricGroups
+
+	// scrapeFixtures adds a fetch of each competition's schedule page.
+	scrapeFixtures bool
+
+	// startupJitter is the most the first scrape is put off by.
+	startupJitter time.Duration
+
+	// concurrency caps how many pages are fetched at once.
+	concurrency int
+
+	// minTeams and maxTeams bound the team count of a plausible standings table.
+	minTeams, maxTeams int
+
+	// written holds, per competition slug, the series set by the last successful scrape.
+	written map[string]series
+
+	// parsed keeps, per page URL, the last document parsed and what came of
+	// it, so a page the fetcher reuses after a 304 isn't parsed again.
+	parsed map[string]parsedPage
+
+	// snapshotPath, when set, persists snapshot across restarts.
+	snapshotPath string
+
+	// snapshot keeps the last parsed Stats per competition slug for /stats.json.
+	snapshotMu sync.RWMutex
+	snapshot   map[string]*Stats
+	updatedAt  time.Time
+
+	// m holds the collectors this scraper writes to; reg is what /metrics serves.
+	m   *metrics
+	reg *prometheus.Registry
+
+	// ready flips once the first scrape succeeds.
+	ready atomic.Bool
+
+	// running guards against overlapping scrapes from the ticker and /scrape,
+	// which would otherwise race on resetting and writing the same gauges.
+	running atomic.Bool
+
+	// failures counts scrapes that failed in a row; past breakerThreshold it
+	// stretches the wait before the next scheduled scrape.
+	failures atomic.Int64
+}
+
+// newScraper builds a scraper for the competitions and limits in cfg.
+func newScraper(cfg Config, fetcher Fetcher, m *metrics, reg *prometheus.Registry) *scraper {
+	s := &scraper{
+		fetcher:         fetcher,
+		m:               m,
+		reg:             reg,
+		competitions:    cfg.competitions,
+		season:          currentSeason,
+		interval:        cfg.ScrapeInterval,
+		timeout:         cfg.ScrapeTimeout,
+		maxCommentBytes: cfg.MaxCommentBytes,
+		maxRows:         cfg.MaxRowsPerTable,
+		groups:          cfg.groups,
+		scrapeFixtures:  cfg.ScrapeFixtures,
+		startupJitter:   cfg.StartupJitter,
+		concurrency:     cfg.Concurrency,
+		minTeams:        cfg.MinTeams,
+		maxTeams:        cfg.MaxTeams,
+		snapshotPath:    cfg.SnapshotFile,
+		written:         map[string]series{},
+		parsed:          map[string]parsedPage{},
+		snapshot:        map[string]*Stats{},
+	}
+	if cfg.Season != "" {
+		s.season = cfg.Season
+	}
+	// Set here rather than when the ticker starts, so a one-shot -textfile
+	// run exports it too.
+	m.scrapeInterval.Set(cfg.ScrapeInterval.Seconds())
+	return s
+}
+
+// errScrapeInProgress is returned when a scrape is requested while one is still running.
+var errScrapeInProgress = errors.New("scrape already running")
+
+// scrapeFBref scrapes every configured competition and populates the gauges.
+// A failing competition does not stop the others and keeps the series from its
+// last good scrape, since nothing is written until its pages have been fetched,
+// parsed and validated. All errors are returned together; success tracking is
+// left to the caller so failures can be reacted to rather than only logged.
+// What was parsed is returned per competition slug, including competitions
+// that then failed validation, for -dry-run to report.
+func (s *scraper) scrapeFBref(ctx context.Context) (map[string]*Stats, error) {
+	if !s.running.CompareAndSwap(false, true) {
+		log.Println("[WARN] scrape already running, skipping")
+		s.m.scrapesSkipped.Inc()
+		return nil, errScrapeInProgress
+	}
+	defer s.running.Store(false)
+
 	start := time.Now()
-	defer func() { scrapeDuration.Set(time.Since(start).Seconds()) }()
-
-	log.Println("[INFO] Starting FBref Premier League scrape...")
-
-	// Reset metrics
-	topScorer.Reset()
-	topAssists.Reset()
-	cleanSheets.Reset()
-	teamPoints.Reset()
-	teamGoalsFor.Reset()
-	teamGoalsAgainst.Reset()
-	teamWins.Reset()
-	teamDraws.Reset()
-	teamLosses.Reset()
-
-	doc, err := fetchHTML("https://fbref.com/en/comps/9/Premier-League-Stats")
+	var urls []string
+	for _, c := range s.competitions {
+		urls = append(urls, c.URL)
+		if s.scrapeFixtures {
+			urls = append(urls, c.ScheduleURL)
+		}
+	}
+	log.Printf("[INFO] Starting FBref scrape of %d pages (concurrency %d)...", len(urls), s.concurrency)
+	pages := s.fetchAll(ctx, urls)
+	fetched := time.Now()
+
+	// Parsing and metric writes stay on this goroutine, one competition at a
+	// time, so the series bookkeeping needs no locking.
+	var errs []error
+	parsed := map[string]*Stats{}
+	for _, c := range s.competitions {
+		stats, err := s.scrapeCompetition(c, pages)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", c.Slug, err))
+			if _, ok := s.written[c.Slug]; ok {
+				log.Printf("[WARN] Keeping the last good %s metrics after a failed scrape", c.Slug)
+			}
+		}
+		if stats != nil {
+			parsed[c.Slug] = stats
+		} else {
+			stats = &Stats{}
+		}
+		// Set on every scrape, failed competitions included, so a layout
+		// change that empties a table is visible as a drop to 0.
+		s.m.scrapedPlayers.WithLabelValues(c.Slug).Set(float64(len(stats.Players)))
+		s.m.scrapedTeams.WithLabelValues(c.Slug).Set(float64(len(stats.Teams)))
+		s.m.scrapedKeepers.WithLabelValues(c.Slug).Set(float64(len(stats.Goalkeepers)))
+	}
+	s.m.fetchDuration.Set(fetched.Sub(start).Seconds())
+	s.m.parseDuration.Set(time.Since(fetched).Seconds())
+	s.m.scrapeDuration.Set(time.Since(start).Seconds())
+	return parsed, errors.Join(errs...)
+}
+
+// fetchResult is the outcome of fetching one page.
+type fetchResult struct {
+	doc *goquery.Document
+	err error
+}
+
+// fetchAll fetches urls with up to s.concurrency requests in flight.
+func (s *scraper) fetchAll(ctx context.Context, urls []string) map[string]fetchResult {
+	results := make([]fetchResult, len(urls))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(s.concurrency, len(urls)) {
+		wg.Go(func() {
+			for i := range jobs {
+				doc, err := s.fetcher.Fetch(ctx, urls[i])
+				results[i] = fetchResult{doc: doc, err: err}
+			}
+		})
+	}
+	for i := range urls {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	pages := make(map[string]fetchResult, len(urls))
+	for i, u := range urls {
+		pages[u] = results[i]
+	}
+	return pages
+}
+
+// scrapeCompetition parses the competition's fetched pages and writes its
+// series, returning what was parsed. A dataset that fails validation is
+// returned along with the error, but none of its series are written.
+func (s *scraper) scrapeCompetition(c competition, pages map[string]fetchResult) (*Stats, error) {
+	page := pages[c.URL]
+	if page.err != nil {
+		return nil, fmt.Errorf("fetching %s: %w", redactURL(c.URL), page.err)
+	}
+	stats, err := s.pageStats(c, page.doc)
 	if err != nil {
-		log.Printf("[ERROR] Failed to fetch HTML: %v", err)
-		scrapeSuccess.Set(0)
-		return
+		return nil, err
 	}
+	if s.scrapeFixtures {
+		if err := s.addSchedule(c, stats, pages); err != nil {
+			return nil, err
+		}
+	}
+	for table, n := range stats.ParseErrors {
+		s.m.parseErrors.WithLabelValues(table).Add(float64(n))
+	}
+	// A standings table with a handful of rows means the parse went wrong;
+	// keep the previous series rather than overwrite them with it.
+	if n := len(stats.Teams); s.groups.teams && !c.PlayersOnly && (n < s.minTeams || n > s.maxTeams) {
+		return stats, fmt.Errorf("implausible team count %d (expected %d-%d)", n, s.minTeams, s.maxTeams)
+	}
+
+	s.writeSeries(c, stats, time.Now())
+	return stats, nil
+}
+
+// parsedPage is the last document parsed for a URL and what it parsed to.
+type parsedPage struct {
+	doc   *goquery.Document
+	stats *Stats
+	sched *Schedule
+}
+
+// pageStats parses the competition's stats page, or reuses the last parse when
+// the fetcher hands back the same document after a 304. Either way the result
+// is a copy the caller may add the schedule to. A reused parse carries no
+// ParseErrors, as those were counted when the page was first parsed.
+func (s *scraper) pageStats(c competition, doc *goquery.Document) (*Stats, error) {
+	p, ok := s.parsed[c.URL]
+	if !ok || p.doc != doc {
+		htmlStr, err := doc.Html()
+		if err != nil {
+			return nil, fmt.Errorf("rendering HTML: %w", err)
+		}
+		s.m.fetchedBytes.WithLabelValues(c.Slug).Set(float64(len(htmlStr)))
+		allDocs := dedupeTables(doc, extractCommentTables(htmlStr, s.maxCommentBytes))
+
+		stats, err := parseStats(allDocs, s.maxRows, s.groups)
+		if err != nil {
+			return nil, fmt.Errorf("parsing stats: %w", err)
+		}
+		if stats.AdvancedTeamTable {
+			log.Printf("[INFO] %s: found the squad stats table", c.Slug)
+		} else {
+			log.Printf("[INFO] %s: no squad stats table on the page; skipping the team squad metrics", c.Slug)
+		}
+		s.parsed[c.URL] = parsedPage{doc: doc, stats: stats}
+		cp := *stats
+		cp.Teams = slices.Clone(stats.Teams)
+		cp.ParseErrors = maps.Clone(stats.ParseErrors)
+		return &cp, nil
+	}
+	log.Printf("[INFO] %s: stats page not modified, reusing its last parse", c.Slug)
+	cp := *p.stats
+	cp.Teams = slices.Clone(p.stats.Teams)
+	cp.ParseErrors = map[string]int{}
+	return &cp, nil
+}
+
+// writeSeries sets every series for a competition from stats, deletes the ones
+// that are gone since the last write, and records stats as taken at 'at'.
+func (s *scraper) writeSeries(c competition, stats *Stats, at time.Time) {
+	sr := series{}
+	minutesCount, defensiveCount := 0, 0
+	ownGoals := 0.0
+	for _, p := range stats.Players {
+		sr.setIf(s.m.topScorer, p.Goals, p.Player, p.Team, s.season, c.Slug, p.League, p.Position, p.Nationality)
+		sr.setIf(s.m.topAssists, p.Assists, p.Player, p.Team, s.season, c.Slug, p.League, p.Position, p.Nationality)
+		sr.setIf(s.m.goalInvolvements, p.Involvements, p.Player, p.Team, s.season, c.Slug, p.League)
+		sr.setIf(s.m.yellowCards, p.YellowCards, p.Player, p.Team, s.season, c.Slug, p.League)
+		sr.setIf(s.m.redCards, p.RedCards, p.Player, p.Team, s.season, c.Slug, p.League)
+		sr.setIf(s.m.playerMinutes, p.Minutes, p.Player, p.Team, s.season, c.Slug, p.League)
+		sr.setIf(s.m.playerAppearances, p.Appearances, p.Player, p.Team, s.season, c.Slug, p.League)
+		sr.setIf(s.m.playerStarts, p.Starts, p.Player, p.Team, s.season, c.Slug, p.League)
+		sr.setIf(s.m.playerAge, p.AgeYears, p.Player, p.Team, s.season, c.Slug, p.League)
+		sr.setIf(s.m.playerXG, p.XG, p.Player, p.Team, s.season, c.Slug, p.League)
+		sr.setIf(s.m.playerXA, p.XA, p.Player, p.Team, s.season, c.Slug, p.League)
+		sr.setIf(s.m.playerNPXG, p.NPXG, p.Player, p.Team, s.season, c.Slug, p.League)
+		sr.setIf(s.m.playerNPXGPerShot, p.NPXGPerShot, p.Player, p.Team, s.season, c.Slug, p.League)
+		sr.setIf(s.m.playerShots, p.Shots, p.Player, p.Team, s.season, c.Slug, p.League)
+		sr.setIf(s.m.playerShotsOnTarget, p.ShotsOnTarget, p.Player, p.Team, s.season, c.Slug, p.League)
+		sr.setIf(s.m.playerGoalsPer90, p.GoalsPer90, p.Player, p.Team, s.season, c.Slug, p.League)
+		sr.setIf(s.m.playerAssistsPer90, p.AssistsPer90, p.Player, p.Team, s.season, c.Slug, p.League)
+		sr.setIf(s.m.playerPassPct, p.PassPct, p.Player, p.Team, s.season, c.Slug, p.League)
+		sr.setIf(s.m.playerTackles, p.Tackles, p.Player, p.Team, s.season, c.Slug, p.League)
+		sr.setIf(s.m.playerInterceptions, p.Interceptions, p.Player, p.Team, s.season, c.Slug, p.League)
+		sr.setIf(s.m.playerOwnGoals, p.OwnGoals, p.Player, p.Team, s.season, c.Slug, p.League)
+		sr.setIf(s.m.playerKeyPasses, p.KeyPasses, p.Player, p.Team, s.season, c.Slug, p.League)
+		sr.setIf(s.m.playerSCA, p.SCA, p.Player, p.Team, s.season, c.Slug, p.League)
+		sr.setIf(s.m.playerProgCarries, p.ProgCarries, p.Player, p.Team, s.season, c.Slug, p.League)
+		sr.setIf(s.m.playerProgPasses, p.ProgPasses, p.Player, p.Team, s.season, c.Slug, p.League)
+		if p.Minutes != nil && *p.Minutes > 0 {
+			minutesCount++
+		}
+		if p.Tackles != nil {
+			defensiveCount++
+		}
+		if p.OwnGoals != nil {
+			ownGoals += *p.OwnGoals
+		}
+	}
+	for _, gk := range stats.Goalkeepers {
+		sr.setIf(s.m.cleanSheets, gk.CleanSheets, gk.Player, gk.Team, s.season, c.Slug)
+		sr.setIf(s.m.gkSaves, gk.Saves, gk.Player, gk.Team, s.season, c.Slug)
+		sr.setIf(s.m.gkSavePct, gk.SavePct, gk.Player, gk.Team, s.season, c.Slug)
+	}
+	for _, t := range stats.Teams {
+		short := shortTeamName(t.Team)
+		sr.set(s.m.teamPoints, t.Points, t.Team, s.season, c.Slug, short)
+		sr.set(s.m.teamGoalsFor, t.GoalsFor, t.Team, s.season, c.Slug, short)
+		sr.set(s.m.teamGoalsAgainst, t.GoalsAgainst, t.Team, s.season, c.Slug, short)
+		sr.set(s.m.teamWins, t.Wins, t.Team, s.season, c.Slug, short)
+		sr.set(s.m.teamDraws, t.Draws, t.Team, s.season, c.Slug, short)
+		sr.set(s.m.teamLosses, t.Losses, t.Team, s.season, c.Slug, short)
+		sr.set(s.m.teamRank, t.Rank, t.Team, s.season, c.Slug, short)
+		sr.set(s.m.teamMatches, t.Matches, t.Team, s.season, c.Slug, short)
+		sr.set(s.m.teamGoalDiff, t.GoalDiff, t.Team, s.season, c.Slug, short)
+		sr.setIf(s.m.teamXG, t.XG, t.Team, s.season, c.Slug, short)
+		sr.setIf(s.m.teamXGA, t.XGA, t.Team, s.season, c.Slug, short)
+		sr.set(s.m.teamPPG, t.PointsPerGame, t.Team, s.season, c.Slug, short)
+		sr.set(s.m.teamWinPct, t.WinPct, t.Team, s.season, c.Slug, short)
+		sr.set(s.m.teamGAPerGame, t.GoalsAgainstPerGame, t.Team, s.season, c.Slug, short)
+		sr.setIf(s.m.teamXPoints, t.XPoints, t.Team, s.season, c.Slug, short)
+		sr.setIf(s.m.teamFormPoints, t.FormPoints, t.Team, s.season, c.Slug, short)
+		sr.setIf(s.m.teamPointsHome, t.PointsHome, t.Team, s.season, c.Slug, short)
+		sr.setIf(s.m.teamPointsAway, t.PointsAway, t.Team, s.season, c.Slug, short)
+		sr.setIf(s.m.teamAttendance, t.AvgAttendance, t.Team, s.season, c.Slug, short)
+		sr.setIf(s.m.teamPossession, t.Possession, t.Team, s.season, c.Slug, short)
+		sr.setIf(s.m.teamXA, t.XA, t.Team, s.season, c.Slug, short)
+		sr.setIf(s.m.teamSquadSize, t.SquadSize, t.Team, s.season, c.Slug, short)
+		sr.setIf(s.m.teamAvgAge, t.AvgAge, t.Team, s.season, c.Slug, short)
+		sr.setIf(s.m.teamCleanSheets, t.CleanSheets, t.Team, s.season, c.Slug, short)
+	}
+	for _, f := range stats.Fixtures {
+		sr.set(s.m.fixtureScheduled, 1, f.Home, f.Away, f.Date, s.season, c.Slug)
+		if f.Kickoff != nil {
+			sr.set(s.m.fixtureKickoff, float64(f.Kickoff.Unix()), f.Home, f.Away, f.Date, s.season, c.Slug)
+		}
+	}
+	for _, r := range stats.Results {
+		sr.set(s.m.matchHomeGoals, r.HomeGoals, r.Home, r.Away, r.Date, s.season, c.Slug)
+		sr.set(s.m.matchAwayGoals, r.AwayGoals, r.Home, r.Away, r.Date, s.season, c.Slug)
+	}
+
+	sr.deleteStale(s.written[c.Slug])
+	s.written[c.Slug] = sr
+
+	s.snapshotMu.Lock()
+	s.snapshot[c.Slug] = stats
+	s.updatedAt = at
+	s.snapshotMu.Unlock()
+
+	log.Printf("[INFO] Scraped %s: %d players (%d with minutes, %d with defensive actions, %.0f own goals), %d teams, %d goalkeepers",
+		c.Slug, len(stats.Players), minutesCount, defensiveCount, ownGoals, len(stats.Teams), len(stats.Goalkeepers))
+}
+
+// addSchedule merges what the competition's fixtures page carries into stats.
+// A failed fetch fails the competition, so its metrics keep their last
+// complete values rather than losing just the fixture series.
+func (s *scraper) addSchedule(c competition, stats *Stats, pages map[string]fetchResult) error {
+	page := pages[c.ScheduleURL]
+	if page.err != nil {
+		return fmt.Errorf("fetching %s: %w", redactURL(c.ScheduleURL), page.err)
+	}
+	// As with the stats page, a reused document keeps its last parse and its
+	// errors aren't counted again.
+	if p, ok := s.parsed[c.ScheduleURL]; ok && p.doc == page.doc {
+		mergeSchedule(stats, p.sched)
+		return nil
+	}
+	sched := parseSchedule(page.doc, s.maxRows)
+	s.parsed[c.ScheduleURL] = parsedPage{doc: page.doc, sched: sched}
+	for table, n := range sched.ParseErrors {
+		stats.ParseErrors[table] += n
+	}
+	mergeSchedule(stats, sched)
+	return nil
+}
 
-	htmlStr, _ := doc.Html()
-	allDocs := append([]*goquery.Document{doc}, extractCommentTables(htmlStr)...)
-
-	playerCount, teamCount, gkCount := 0, 0, 0
-
-	for _, d := range allDocs {
-		// --- Player stats ---
-		if d.Find("th[data-stat='player']").Length() > 0 && d.Find("td[data-stat='goals']").Length() > 0 {
-			d.Find("tbody tr").Each(func(_ int, s *goquery.Selection) {
-				player := strings.TrimSpace(s.Find("td[data-stat='player']").Text())
-				team := strings.TrimSpace(s.Find("td[data-stat='team']").Text())
-				goals, _ := strconv.ParseFloat(strings.TrimSpace(s.Find("td[data-stat='goals']").Text()), 64)
-				assists, _ := strconv.ParseFloat(strings.TrimSpace(s.Find("td[data-stat='assists']").Text()), 64)
-				if player != "" && team != "" {
-					topScorer.WithLabelValues(player, team).Set(goals)
-					topAssists.WithLabelValues(player, team).Set(assists)
-					playerCount++
-				}
-			})
-		}
-
-		// --- Goalkeeper clean sheets ---
-		if d.Find("th[data-stat='player']").Length() > 0 && d.Find("td[data-stat='clean_sheets']").Length() > 0 {
-			d.Find("tbody tr").Each(func(_ int, s *goquery.Selection) {
-				player := strings.TrimSpace(s.Find("td[data-stat='player']").Text())
-				team := strings.TrimSpace(s.Find("td[data-stat='team']").Text())
-				cs, _ := strconv.ParseFloat(strings.TrimSpace(s.Find("td[data-stat='clean_sheets']").Text()), 64)
-				if player != "" && team != "" {
-					cleanSheets.WithLabelValues(player, team).Set(cs)
-					gkCount++
-				}
-			})
-		}
-
-		// --- Team stats ---
-		if d.Find("th[data-stat='team']").Length() > 0 && d.Find("td[data-stat='points']").Length() > 0 {
-			d.Find("tbody tr").Each(func(_ int, s *goquery.Selection) {
-				team := strings.TrimSpace(s.Find("th[data-stat='team']").Text())
-				if team == "" {
-					return
-				}
-				points, _ := strconv.ParseFloat(strings.TrimSpace(s.Find("td[data-stat='points']").Text()), 64)
-				goalsFor, _ := strconv.ParseFloat(strings.TrimSpace(s.Find("td[data-stat='goals_for']").Text()), 64)
-				goalsAgainst, _ := strconv.ParseFloat(strings.TrimSpace(s.Find("td[data-stat='goals_against']").Text()), 64)
-				wins, _ := strconv.ParseFloat(strings.TrimSpace(s.Find("td[data-stat='wins']").Text()), 64)
-				draws, _ := strconv.ParseFloat(strings.TrimSpace(s.Find("td[data-stat='draws']").Text()), 64)
-				losses, _ := strconv.ParseFloat(strings.TrimSpace(s.Find("td[data-stat='losses']").Text()), 64)
-
-				teamPoints.WithLabelValues(team).Set(points)
-				teamGoalsFor.WithLabelValues(team).Set(goalsFor)
-				teamGoalsAgainst.WithLabelValues(team).Set(goalsAgainst)
-				teamWins.WithLabelValues(team).Set(wins)
-				teamDraws.WithLabelValues(team).Set(draws)
-				teamLosses.WithLabelValues(team).Set(losses)
-				teamCount++
-			})
-		}
-	}
-
-	log.Printf("[INFO] Scraped %d players, %d teams, %d goalkeepers", playerCount, teamCount, gkCount)
-	scrapeSuccess.Set(1)
+// mergeSchedule copies the schedule's attendance, fixtures and results into stats.
+func mergeSchedule(stats *Stats, sched *Schedule) {
+	for i := range stats.Teams {
+		if v, ok := sched.Attendance[stats.Teams[i].Team]; ok {
+			stats.Teams[i].AvgAttendance = ptr(v)
+		}
+	}
+	stats.Fixtures = sched.Upcoming
+	stats.Results = sched.Results
 }
 
 // --------------------- Exporter Start ---------------------
 
-func startScraping() {
-	scrapeFBref()
-	ticker := time.NewTicker(1 * time.Hour)
+// runScrape performs one scrape bounded by s.timeout, so that a hung request
+// can never run into the next tick, and records the outcome.
+func (s *scraper) runScrape(ctx context.Context) error {
+	scrapeCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	_, err := s.scrapeFBref(scrapeCtx)
+	if errors.Is(err, errScrapeInProgress) {
+		return err
+	}
+	// Not scrapeCtx: after a scrape that ran out its deadline that has
+	// already expired, and the failure would never reach the gateway.
+	defer s.pushMetrics(ctx)
+	if err != nil {
+		log.Printf("[ERROR] Scrape failed: %v", err)
+		s.m.scrapeSuccess.Set(0)
+		s.m.scrapesTotal.WithLabelValues("failure").Inc()
+		n := s.failures.Add(1)
+		wait := scrapeBackoff(s.interval, n)
+		s.m.scrapeBackoff.Set((wait - s.interval).Seconds())
+		if wait > s.interval {
+			log.Printf("[WARN] %d scrapes in a row have failed; backing off, next scheduled scrape in %s", n, wait)
+		}
+		return err
+	}
+	if s.failures.Swap(0) >= breakerThreshold {
+		log.Println("[INFO] Scrape succeeded, back to the normal interval")
+	}
+	s.m.scrapeBackoff.Set(0)
+	s.m.scrapeSuccess.Set(1)
+	s.m.scrapesTotal.WithLabelValues("success").Inc()
+	now := time.Now()
+	s.m.lastScrapeTime.Set(float64(now.Unix()))
+	s.m.sinceSuccess.succeeded(now)
+	if s.snapshotPath != "" {
+		if err := s.saveSnapshot(s.snapshotPath); err != nil {
+			log.Printf("[WARN] Failed to save snapshot to %s: %v", s.snapshotPath, err)
+		}
+	}
+	s.ready.Store(true)
+	return nil
+}
+
+// pushMetrics pushes to the Pushgateway when one is configured. It runs after
+// failed scrapes too, so the gateway sees fbref_scrape_success drop, and gets
+// pushTimeout of its own rather than what is left of the scrape's deadline.
+func (s *scraper) pushMetrics(ctx context.Context) {
+	if s.pusher == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, pushTimeout)
+	defer cancel()
+	if err := s.pusher.push(ctx); err != nil {
+		log.Printf("[ERROR] %v", err)
+	}
+}
+
+// dryRun scrapes once and prints what was parsed per competition to stdout,
+// as text or JSON. It returns the process exit code: 1 when any competition
+// failed, including failing the team-count sanity check. Counts are printed
+// for competitions that failed validation too, since in that case they're
+// what explains the failure.
+func (s *scraper) dryRun(ctx context.Context, format string) int {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	stats, err := s.scrapeFBref(ctx)
+
+	type summary struct {
+		Players     int `json:"players"`
+		Teams       int `json:"teams"`
+		Goalkeepers int `json:"goalkeepers"`
+	}
+	parsed := map[string]summary{}
+	for slug, st := range stats {
+		parsed[slug] = summary{Players: len(st.Players), Teams: len(st.Teams), Goalkeepers: len(st.Goalkeepers)}
+	}
+
+	if format == "json" {
+		out := map[string]any{"season": s.season, "competitions": parsed, "ok": err == nil}
+		if err != nil {
+			out["error"] = err.Error()
+		}
+		json.NewEncoder(os.Stdout).Encode(out)
+	} else {
+		for _, c := range s.competitions {
+			if sum, ok := parsed[c.Slug]; ok {
+				fmt.Printf("%s (season %s): %d players, %d teams, %d goalkeepers\n", c.Slug, s.season, sum.Players, sum.Teams, sum.Goalkeepers)
+			} else {
+				fmt.Printf("%s (season %s): nothing parsed\n", c.Slug, s.season)
+			}
+		}
+		if err != nil {
+			fmt.Printf("FAILED: %v\n", err)
+		}
+	}
+	if err != nil {
+		return 1
+	}
+	return 0
+}
+
+// startScraping scrapes now and then once per interval, waiting longer while
+// scrapes keep failing (see scrapeBackoff). With -startup-jitter the first
+// scrape is delayed instead and runs in the background, so /metrics and the
+// health endpoints come up straight away.
+func (s *scraper) startScraping(ctx context.Context) {
+	if s.startupJitter <= 0 {
+		s.runScrape(ctx)
+	}
 	go func() {
-		for range ticker.C {
-			scrapeFBref()
+		if s.startupJitter > 0 {
+			delay := time.Duration(rand.Int63n(int64(s.startupJitter) + 1))
+			log.Printf("[INFO] Delaying the first scrape by %s (startup jitter up to %s)", delay.Round(time.Millisecond), s.startupJitter)
+			if sleepCtx(ctx, delay) != nil {
+				return
+			}
+			s.runScrape(ctx)
+		}
+		for {
+			t := time.NewTimer(scrapeBackoff(s.interval, s.failures.Load()))
+			select {
+			case <-ctx.Done():
+				t.Stop()
+				return
+			case <-t.C:
+				s.runScrape(ctx)
+			}
 		}
 	}()
 }
 
+// breakerThreshold is how many scrapes in a row may fail before the exporter
+// starts backing off; maxScrapeBackoff caps the backed-off interval.
+const (
+	breakerThreshold = 3
+	maxScrapeBackoff = 6 * time.Hour
+)
+
+// scrapeBackoff returns the wait before the next scheduled scrape after
+// failures consecutive failures: the interval until breakerThreshold is
+// reached, then doubling with each further failure up to maxScrapeBackoff
+// (or the interval itself, if that is longer).
+func scrapeBackoff(interval time.Duration, failures int64) time.Duration {
+	wait := interval
+	for n := failures - breakerThreshold; n >= 0 && wait < maxScrapeBackoff; n-- {
+		wait *= 2
+	}
+	return max(min(wait, maxScrapeBackoff), interval)
+}
+
+// --------------------- HTTP Handlers ---------------------
+
+// healthzHandler reports liveness only; it never looks at scrape state.
+func healthzHandler(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, "ok")
+}
+
+// newMux routes the exporter's endpoints, with the metrics on -metrics-path.
+func (s *scraper) newMux(cfg Config) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.Handle(cfg.MetricsPath, promhttp.HandlerFor(s.reg, promhttp.HandlerOpts{}))
+	mux.HandleFunc("GET /{$}", indexHandler(cfg.MetricsPath))
+	mux.HandleFunc("/healthz", healthzHandler)
+	mux.HandleFunc("/readyz", s.readyzHandler)
+	mux.HandleFunc("POST /scrape", s.scrapeHandler)
+	mux.HandleFunc("GET /stats.json", s.statsHandler)
+	mux.HandleFunc("GET /config", configHandler(cfg))
+	return mux
+}
+
+// indexHandler serves a small landing page at / that links to the metrics,
+// which can be at any -metrics-path.
+func indexHandler(metricsPath string) http.HandlerFunc {
+	page := fmt.Sprintf(`<html><head><title>Premier League exporter</title></head><body>
+<h1>Premier League exporter</h1>
+<p><a href="%s">Metrics</a></p>
+</body></html>
+`, html.EscapeString(metricsPath))
+	return func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, page)
+	}
+}
+
+// readyzHandler returns 503 until the first scrape has populated the metrics.
+func (s *scraper) readyzHandler(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if !s.ready.Load() {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprint(w, "waiting for first successful scrape")
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, "ok")
+}
+
+// scrapeHandler runs a scrape on demand (POST /scrape) and reports how it went.
+func (s *scraper) scrapeHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	start := time.Now()
+	// A client that hangs up mid-scrape mustn't cancel it: that would count
+	// as a failed scrape and trip the backoff.
+	err := s.runScrape(context.WithoutCancel(r.Context()))
+	body := map[string]any{"success": err == nil, "duration_seconds": time.Since(start).Seconds()}
+	switch {
+	case errors.Is(err, errScrapeInProgress):
+		body["error"] = err.Error()
+		w.WriteHeader(http.StatusConflict)
+	case err != nil:
+		body["error"] = err.Error()
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+	json.NewEncoder(w).Encode(body)
+}
+
+// statsHandler serves the most recently parsed stats of each competition as JSON.
+func (s *scraper) statsHandler(w http.ResponseWriter, _ *http.Request) {
+	s.snapshotMu.RLock()
+	defer s.snapshotMu.RUnlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	if len(s.snapshot) == 0 {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(map[string]string{"error": "no successful scrape yet"})
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]any{
+		"season":       s.season,
+		"updated_at":   s.updatedAt.UTC().Format(time.RFC3339),
+		"competitions": s.snapshot,
+	})
+}
+
 // --------------------- Main ---------------------
 
 func main() {
-	const addr = ":2113"
-	l, err := net.Listen("tcp", addr)
+	flag.Parse()
+
+	// Logging is set up before loadConfig so its warnings come out in the
+	// chosen format; loadConfig rejects anything other than text or json.
+	if *logFormat == "json" {
+		log.SetFlags(0)
+		log.SetOutput(jsonLogWriter{out: os.Stderr})
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		log.Fatalf("[FATAL] %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	addr := cfg.ListenAddress
+	if !cfg.PushOnly && !cfg.DryRun && cfg.Textfile == "" {
+		l, err := net.Listen("tcp", addr)
+		if err != nil {
+			log.Fatalf("[FATAL] Port %s already in use: %v", addr, err)
+		}
+		l.Close()
+	}
+
+	agents, err := loadUserAgents(cfg.UserAgents, cfg.UserAgentsFile)
 	if err != nil {
-		log.Fatalf("[FATAL] Port %s already in use: %v", addr, err)
+		log.Fatalf("[FATAL] %v", err)
+	}
+	normalizeLabels = cfg.NormalizeLabels
+	if cfg.TeamAliasesFile != "" {
+		if err := loadTeamAliases(cfg.TeamAliasesFile); err != nil {
+			log.Fatalf("[FATAL] %v", err)
+		}
+	}
+	if cfg.TeamShortNamesFile != "" {
+		if err := loadTeamShortNames(cfg.TeamShortNamesFile); err != nil {
+			log.Fatalf("[FATAL] %v", err)
+		}
+	}
+	if cfg.SelectorsFile != "" {
+		if err := loadSelectors(cfg.SelectorsFile); err != nil {
+			log.Fatalf("[FATAL] %v", err)
+		}
+		log.Printf("[INFO] Loaded selector overrides from %s", cfg.SelectorsFile)
+	}
+	tlsConfig, err := newTLSConfig(cfg.CAFile, cfg.InsecureSkipVerify)
+	if err != nil {
+		log.Fatalf("[FATAL] Invalid -ca-file %s: %v", cfg.CAFile, err)
+	}
+	if cfg.InsecureSkipVerify {
+		log.Println("[WARN] !!! TLS certificate verification is DISABLED (-insecure-skip-verify); fetched pages can be tampered with !!!")
+	}
+	var cache *diskCache
+	if cfg.CacheDir != "" {
+		if cache, err = newDiskCache(cfg.CacheDir, cfg.CacheTTL); err != nil {
+			log.Fatalf("[FATAL] Cannot use cache directory %s: %v", cfg.CacheDir, err)
+		}
+		log.Printf("[INFO] Caching pages in %s for %s", cfg.CacheDir, cfg.CacheTTL)
 	}
-	l.Close()
 
-	log.Printf("[INFO] Starting Premier League metrics exporter on %s", addr)
-	startScraping()
+	log.Printf("[INFO] Starting Premier League metrics exporter %s (%s) on %s (scrape interval %s)", version, commit, addr, cfg.ScrapeInterval)
+	// A registry of our own instead of the default one. The go_* and process_*
+	// series the default registry came with are added back unless disabled.
+	reg := prometheus.NewRegistry()
+	// node_exporter has go_* and process_* series of its own, and its
+	// textfile collector rejects duplicates.
+	if !cfg.DisableGoMetrics && cfg.Textfile == "" {
+		reg.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	}
+	m := newMetrics(reg, cfg.groups)
+	m.buildInfo.WithLabelValues(version, commit, runtime.Version()).Set(1)
+	var fetcher Fetcher = newHTTPFetcher(cfg, agents, tlsConfig, cache, m)
+	if cfg.HTMLFile != "" {
+		log.Printf("[INFO] Reading pages from %s instead of fetching", cfg.HTMLFile)
+		fetcher = fileFetcher{path: cfg.HTMLFile}
+	}
+	s := newScraper(cfg, fetcher, m, reg)
+	if cfg.groups.players {
+		reg.MustRegister(newAveragesCollector(s))
+	}
+	for _, c := range s.competitions {
+		log.Printf("[INFO] Scraping %s from %s (season %s)", c.Slug, redactURL(c.URL), s.season)
+	}
+	if cfg.DryRun {
+		os.Exit(s.dryRun(ctx, cfg.LogFormat))
+	}
+	if cfg.Textfile != "" {
+		os.Exit(s.writeTextfile(ctx, reg, cfg.Textfile))
+	}
+	if cfg.SnapshotFile != "" {
+		if err := s.loadSnapshot(cfg.SnapshotFile); err != nil {
+			log.Printf("[WARN] Not restoring from snapshot: %v", err)
+		}
+	}
+	if cfg.PushgatewayURL != "" {
+		s.pusher = newGatewayPusher(cfg.PushgatewayURL, reg, cfg.MaxRetries, m.pushErrors)
+		log.Printf("[INFO] Pushing metrics to %s", redactURL(cfg.PushgatewayURL))
+	}
+	s.startScraping(ctx)
+
+	if cfg.PushOnly {
+		<-ctx.Done()
+		log.Println("[INFO] Shutting down...")
+		return
+	}
+
+	srv := &http.Server{Addr: addr, Handler: s.newMux(cfg)}
+
+	go func() {
+		<-ctx.Done()
+		log.Println("[INFO] Shutting down...")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
 
-	http.Handle("/metrics", promhttp.Handler())
-	if err := http.ListenAndServe(addr, nil); err != nil {
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		log.Fatalf("[FATAL] HTTP server failed: %v", err)
 	}
 }
@@ -0,0 +1,380 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// fetcherFunc adapts a function to Fetcher.
+type fetcherFunc func(ctx context.Context, url string) (*goquery.Document, error)
+
+func (f fetcherFunc) Fetch(ctx context.Context, url string) (*goquery.Document, error) {
+	return f(ctx, url)
+}
+
+// testScraper builds a scraper from cfg, filling in workable defaults, with
+// metrics on a fresh registry.
+func testScraper(t *testing.T, fetcher Fetcher, cfg Config) *scraper {
+	t.Helper()
+	if cfg.groups == (metricGroups{}) {
+		cfg.groups = allGroups
+	}
+	if cfg.Concurrency == 0 {
+		cfg.Concurrency = 1
+	}
+	if cfg.MaxCommentBytes == 0 {
+		cfg.MaxCommentBytes = 4 << 20
+	}
+	if cfg.ScrapeTimeout == 0 {
+		cfg.ScrapeTimeout = time.Minute
+	}
+	reg := prometheus.NewRegistry()
+	return newScraper(cfg, fetcher, newMetrics(reg, cfg.groups), reg)
+}
+
+// A scrape that runs out its deadline must still push, so the gateway sees
+// fbref_scrape_success drop to 0.
+func TestRunScrapePushesAfterDeadline(t *testing.T) {
+	pushed := make(chan string, 1)
+	gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		select {
+		case pushed <- string(body):
+		default:
+		}
+	}))
+	defer gateway.Close()
+
+	hang := fetcherFunc(func(ctx context.Context, _ string) (*goquery.Document, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	s := testScraper(t, hang, Config{
+		ScrapeTimeout: 50 * time.Millisecond,
+		competitions:  []competition{{ID: "9", Slug: "Premier-League", URL: "https://fbref.test/pl"}},
+	})
+	s.pusher = newGatewayPusher(gateway.URL, s.reg, 1, s.m.pushErrors)
+
+	if err := s.runScrape(context.Background()); err == nil {
+		t.Fatal("runScrape succeeded, want a deadline error")
+	}
+	select {
+	case body := <-pushed:
+		// The push is protobuf-encoded; the metric name is enough to know it went out.
+		if !strings.Contains(body, "fbref_scrape_success") {
+			t.Errorf("push did not include fbref_scrape_success")
+		}
+	default:
+		t.Fatal("nothing was pushed after the failed scrape")
+	}
+}
+
+// standingsPage is a minimal stats page: a two-team standings table and a
+// player table hidden in a comment, as FBref serves it.
+const standingsPage = `<html><body>
+<table class="stats_table"><tbody>
+<tr><th data-stat="rank">1</th><th data-stat="team">Arsenal</th><td data-stat="games">8</td><td data-stat="wins">6</td><td data-stat="points">19</td></tr>
+<tr><th data-stat="rank">2</th><th data-stat="team">Chelsea</th><td data-stat="games">8</td><td data-stat="wins">4</td><td data-stat="points">14</td></tr>
+</tbody></table>
+<!--
+<table class="stats_table"><thead><tr><th data-stat="player">Player</th></tr></thead><tbody>
+<tr><td data-stat="player">Bukayo Saka</td><td data-stat="team">Arsenal</td><td data-stat="goals">4</td><td data-stat="assists">3</td></tr>
+</tbody></table>
+-->
+</body></html>`
+
+// pageFetcher serves standingsPage for every URL, counting the fetches.
+func pageFetcher(fetched *atomic.Int64) Fetcher {
+	return fetcherFunc(func(context.Context, string) (*goquery.Document, error) {
+		fetched.Add(1)
+		return goquery.NewDocumentFromReader(strings.NewReader(standingsPage))
+	})
+}
+
+// Fetches run concurrently while parsing and metric writes stay on the
+// scraping goroutine; run with -race to check they don't share state.
+func TestScrapeConcurrentCompetitions(t *testing.T) {
+	var comps []competition
+	for _, slug := range []string{"Premier-League", "Championship", "La-Liga", "Serie-A", "Bundesliga", "Ligue-1"} {
+		comps = append(comps, competition{Slug: slug, URL: "https://fbref.test/" + slug})
+	}
+	var fetched atomic.Int64
+	s := testScraper(t, pageFetcher(&fetched), Config{
+		Concurrency:  4,
+		MinTeams:     1,
+		MaxTeams:     24,
+		competitions: comps,
+	})
+
+	if _, err := s.scrapeFBref(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := fetched.Load(); n != int64(len(comps)) {
+		t.Errorf("fetched %d pages, want %d", n, len(comps))
+	}
+	for _, c := range comps {
+		st := s.snapshot[c.Slug]
+		if st == nil || len(st.Teams) != 2 || len(st.Players) != 1 {
+			t.Errorf("%s: got %+v, want 2 teams and 1 player", c.Slug, st)
+		}
+	}
+}
+
+// A table FBref serves both on the page and in a comment must be parsed once,
+// whether it is matched by id or, without one, by its markup.
+func TestDedupeTables(t *testing.T) {
+	const withID = `<table class="stats_table" id="stats_standard"><tbody><tr><td data-stat="player">Bukayo Saka</td></tr></tbody></table>`
+	const withoutID = `<table class="stats_table"><tbody><tr><td data-stat="player">David Raya</td></tr></tbody></table>`
+	const onlyLive = `<table class="stats_table" id="results"><tbody><tr><th data-stat="team">Arsenal</th></tr></tbody></table>`
+	page := `<html><body>` + onlyLive + withID + withoutID + `<!--` + withID + `--><!--` + withoutID + `--></body></html>`
+
+	doc := mustDocs(t, page)[0]
+	docs := dedupeTables(doc, extractCommentTables(page, 4<<20))
+
+	if len(docs) != 3 {
+		t.Fatalf("got %d documents, want the page and both comments", len(docs))
+	}
+	if n := docs[0].Find("table").Length(); n != 1 {
+		t.Errorf("page kept %d tables, want only the one not repeated in a comment", n)
+	}
+	if docs[0].Find("#results").Length() != 1 {
+		t.Error("page lost the table that only appears live")
+	}
+	total := 0
+	for _, d := range docs {
+		total += d.Find("td[data-stat='player']").Length()
+	}
+	if total != 2 {
+		t.Errorf("found %d player rows across the documents, want each table once (2)", total)
+	}
+	if doc.Find("table").Length() != 3 {
+		t.Error("dedupeTables modified the original document")
+	}
+}
+
+func TestExtractCommentTables(t *testing.T) {
+	table := func(id string) string {
+		return `<table class="stats_table" id="` + id + `"><tbody><tr><td>1</td></tr></tbody></table>`
+	}
+	tests := []struct {
+		name    string
+		html    string
+		maxSize int
+		want    []string
+	}{
+		{
+			name: "commented tables",
+			html: `<div><!--` + table("a") + `--></div><!--` + table("b") + `-->`,
+			want: []string{"a", "b"},
+		},
+		{
+			name: "comment without a stats table",
+			html: `<!-- <p>ad slot</p> --><!--<table id="x"></table>-->`,
+		},
+		{
+			name: "--> inside a script",
+			html: `<script>for (var i = 3; i-->0;) {}</script><!--` + table("a") + `-->`,
+			want: []string{"a"},
+		},
+		{
+			name: "comment markup inside a script",
+			html: `<SCRIPT>document.write("<!--` + table("fake") + `-->")</SCRIPT><!--` + table("a") + `-->`,
+			want: []string{"a"},
+		},
+		{
+			name: "--> inside a style",
+			html: `<style>/* <!-- */ .x { content: "-->"; }</style><!--` + table("a") + `-->`,
+			want: []string{"a"},
+		},
+		{
+			name: "truncated comment",
+			html: `<!--` + table("a") + `--><!--` + table("b"),
+			want: []string{"a"},
+		},
+		{
+			name: "unterminated script",
+			html: `<!--` + table("a") + `--><script>var x = "<!--` + table("b") + `-->";`,
+			want: []string{"a"},
+		},
+		{
+			name:    "comment larger than maxSize",
+			html:    `<!--` + table("a") + `--><!--` + table("big") + strings.Repeat(" ", 200) + `-->`,
+			maxSize: len(table("a")),
+			want:    []string{"a"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			maxSize := tt.maxSize
+			if maxSize == 0 {
+				maxSize = 4 << 20
+			}
+			var got []string
+			for _, d := range extractCommentTables(tt.html, maxSize) {
+				d.Find("table").Each(func(_ int, s *goquery.Selection) {
+					got = append(got, s.AttrOr("id", ""))
+				})
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got tables %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompetitionURLs(t *testing.T) {
+	tests := []struct {
+		name, got, want string
+	}{
+		{"current stats", buildURL("9", "Premier-League", ""), "https://fbref.com/en/comps/9/Premier-League-Stats"},
+		{"past stats", buildURL("9", "Premier-League", "2022-2023"), "https://fbref.com/en/comps/9/2022-2023/2022-2023-Premier-League-Stats"},
+		{"other competition", buildURL("10", "Championship", ""), "https://fbref.com/en/comps/10/Championship-Stats"},
+		{"other competition, past", buildURL("12", "La-Liga", "2019-2020"), "https://fbref.com/en/comps/12/2019-2020/2019-2020-La-Liga-Stats"},
+		{"current schedule", scheduleURL("9", "Premier-League", ""), "https://fbref.com/en/comps/9/schedule/Premier-League-Scores-and-Fixtures"},
+		{"past schedule", scheduleURL("10", "Championship", "2021-2022"), "https://fbref.com/en/comps/10/2021-2022/schedule/2021-2022-Championship-Scores-and-Fixtures"},
+		{"current big 5", big5URL(""), "https://fbref.com/en/comps/Big5/stats/players/Big-5-European-Leagues-Stats"},
+		{"past big 5", big5URL("2022-2023"), "https://fbref.com/en/comps/Big5/2022-2023/stats/players/2022-2023-Big-5-European-Leagues-Stats"},
+		{"default", buildURL("9", "Premier-League", ""), defaultSourceURL},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+// A competition rejected by the team-count check still reports what was
+// parsed, which is what -dry-run prints to explain the failure.
+func TestScrapeReturnsStatsOfRejectedCompetition(t *testing.T) {
+	var fetched atomic.Int64
+	s := testScraper(t, pageFetcher(&fetched), Config{
+		MinTeams:     20,
+		MaxTeams:     24,
+		competitions: []competition{{Slug: "Premier-League", URL: "https://fbref.test/pl"}},
+	})
+	parsed, err := s.scrapeFBref(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "implausible team count 2") {
+		t.Fatalf("got error %v, want the team-count check to fail", err)
+	}
+	st := parsed["Premier-League"]
+	if st == nil || len(st.Teams) != 2 || len(st.Players) != 1 {
+		t.Errorf("got %+v, want the 2 teams and 1 player parsed", st)
+	}
+	if _, ok := s.snapshot["Premier-League"]; ok {
+		t.Error("rejected stats were stored in the snapshot")
+	}
+}
+
+// POST /scrape keeps going when the client goes away.
+func TestScrapeHandlerOutlivesClient(t *testing.T) {
+	page := fetcherFunc(func(ctx context.Context, _ string) (*goquery.Document, error) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return goquery.NewDocumentFromReader(strings.NewReader(standingsPage))
+	})
+	s := testScraper(t, page, Config{
+		MinTeams:     1,
+		MaxTeams:     24,
+		competitions: []competition{{Slug: "Premier-League", URL: "https://fbref.test/pl"}},
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	rec := httptest.NewRecorder()
+	s.scrapeHandler(rec, httptest.NewRequest("POST", "/scrape", nil).WithContext(ctx))
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("got status %d (%s), want 200", rec.Code, rec.Body)
+	}
+	if n := s.failures.Load(); n != 0 {
+		t.Errorf("%d failures recorded, want 0", n)
+	}
+}
+
+// A page the fetcher reuses after a 304 keeps its last parse, so its parse
+// errors are counted once rather than on every scrape.
+func TestScrapeReusesNotModifiedPage(t *testing.T) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(strings.Replace(standingsPage, `"wins">6<`, `"wins">six<`, 1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := testScraper(t, fetcherFunc(func(context.Context, string) (*goquery.Document, error) {
+		return doc, nil
+	}), Config{
+		MinTeams:     1,
+		MaxTeams:     24,
+		competitions: []competition{{Slug: "Premier-League", URL: "https://fbref.test/pl"}},
+	})
+
+	for i := range 2 {
+		if _, err := s.scrapeFBref(context.Background()); err != nil {
+			t.Fatalf("scrape %d: %v", i+1, err)
+		}
+		st := s.snapshot["Premier-League"]
+		if st == nil || len(st.Teams) != 2 || len(st.Players) != 1 {
+			t.Errorf("scrape %d: got %+v, want 2 teams and 1 player", i+1, st)
+		}
+	}
+	if n := testutil.ToFloat64(s.m.parseErrors.WithLabelValues(tableTeam)); n != 1 {
+		t.Errorf("counted %v team parse errors, want 1", n)
+	}
+}
+
+func TestParseCompetitions(t *testing.T) {
+	comps, err := parseCompetitions("9:Premier-League, 514:FA-Cup:cup", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(comps) != 2 || comps[0].PlayersOnly || !comps[1].PlayersOnly || comps[1].Slug != "FA-Cup" {
+		t.Errorf("got %+v, want the league and then the cup marked PlayersOnly", comps)
+	}
+	if comps[1].URL != "https://fbref.com/en/comps/514/FA-Cup-Stats" {
+		t.Errorf("cup URL = %s", comps[1].URL)
+	}
+	for _, spec := range []string{"9", "9:", ":Premier-League", "514:FA-Cup:knockout"} {
+		if _, err := parseCompetitions(spec, ""); err == nil {
+			t.Errorf("%q: parsed, want an error", spec)
+		}
+	}
+}
+
+// A cup has no standings table, so it parses no teams; with the default team
+// bounds its players must still be written.
+func TestScrapeCupWithoutStandings(t *testing.T) {
+	page := fetcherFunc(func(context.Context, string) (*goquery.Document, error) {
+		return goquery.NewDocumentFromReader(strings.NewReader(`<html><body><table class="stats_table"><thead><tr><th data-stat="player">Player</th></tr></thead><tbody>
+<tr><td data-stat="player">Bukayo Saka</td><td data-stat="team">Arsenal</td><td data-stat="goals">2</td><td data-stat="assists">1</td></tr>
+</tbody></table></body></html>`))
+	})
+	comps, err := parseCompetitions("514:FA-Cup:cup", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := testScraper(t, page, Config{MinTeams: 20, MaxTeams: 24, competitions: comps})
+	if _, err := s.scrapeFBref(context.Background()); err != nil {
+		t.Fatalf("cup scrape failed: %v", err)
+	}
+	if st := s.snapshot["FA-Cup"]; st == nil || len(st.Players) != 1 {
+		t.Errorf("got %+v, want the cup's player stored", st)
+	}
+}
+
+// fbref_scrape_interval_seconds is set as soon as the scraper exists, so a
+// one-shot -textfile run exports it too.
+func TestNewScraperSetsScrapeInterval(t *testing.T) {
+	s := testScraper(t, fetcherFunc(nil), Config{ScrapeInterval: 30 * time.Minute})
+	if got := testutil.ToFloat64(s.m.scrapeInterval); got != 1800 {
+		t.Errorf("fbref_scrape_interval_seconds = %v, want 1800", got)
+	}
+}
@@ -0,0 +1,350 @@
+package main
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// --------------------- Metrics Definitions ---------------------
+
+var (
+	// Every player and team series carries the season and competition it was
+	// scraped for so that exporters scraping different seasons or leagues never collide.
+	// Player series also carry the league from FBref's big-5 page (-source big5);
+	// it is empty, and so absent in Prometheus, for single-competition pages.
+	playerLabels = []string{"player", "team", "season", "competition", "league"}
+
+	// Goalkeepers only come from single-competition pages and have no league.
+	keeperLabels = []string{"player", "team", "season", "competition"}
+
+	// Team series also carry a short_name such as "BHA" for compact panels. It is
+	// fixed per team, so it adds no series.
+	teamLabels = []string{"team", "season", "competition", "short_name"}
+
+	// Goals and assists additionally carry position and nationality for positional
+	// filtering. Both are low-cardinality attributes of the player (a handful of
+	// positions, ~50 nations in a league), so they add at most one series per
+	// player rather than multiplying the series count — except when FBref changes
+	// a player's listed position mid-season, which briefly leaves two series.
+	playerInfoLabels = []string{"player", "team", "season", "competition", "league", "position", "nationality"}
+
+	// fixtureLabels identify a match; the date keeps rearranged fixtures
+	// between the same clubs apart. Played matches get one series per score
+	// gauge, so by the end of a 20-team season there are 380 of each.
+	fixtureLabels = []string{"home", "away", "date", "season", "competition"}
+)
+
+// metrics holds every collector one scraper writes to. Each scraper gets its own
+// set registered into its own registry, so several can live in one process.
+type metrics struct {
+	// Player-level metrics
+	topScorer           *prometheus.GaugeVec
+	topAssists          *prometheus.GaugeVec
+	goalInvolvements    *prometheus.GaugeVec
+	yellowCards         *prometheus.GaugeVec
+	redCards            *prometheus.GaugeVec
+	playerMinutes       *prometheus.GaugeVec
+	playerAppearances   *prometheus.GaugeVec
+	playerStarts        *prometheus.GaugeVec
+	playerAge           *prometheus.GaugeVec
+	playerXG            *prometheus.GaugeVec
+	playerXA            *prometheus.GaugeVec
+	playerNPXG          *prometheus.GaugeVec
+	playerNPXGPerShot   *prometheus.GaugeVec
+	playerShots         *prometheus.GaugeVec
+	playerShotsOnTarget *prometheus.GaugeVec
+	playerGoalsPer90    *prometheus.GaugeVec
+	playerAssistsPer90  *prometheus.GaugeVec
+	playerPassPct       *prometheus.GaugeVec
+	playerTackles       *prometheus.GaugeVec
+	playerInterceptions *prometheus.GaugeVec
+	playerOwnGoals      *prometheus.GaugeVec
+	playerKeyPasses     *prometheus.GaugeVec
+	playerSCA           *prometheus.GaugeVec
+	playerProgCarries   *prometheus.GaugeVec
+	playerProgPasses    *prometheus.GaugeVec
+	cleanSheets         *prometheus.GaugeVec
+	gkSaves             *prometheus.GaugeVec
+	gkSavePct           *prometheus.GaugeVec
+
+	// Team-level metrics
+	teamPoints       *prometheus.GaugeVec
+	teamGoalsFor     *prometheus.GaugeVec
+	teamGoalsAgainst *prometheus.GaugeVec
+	teamWins         *prometheus.GaugeVec
+	teamDraws        *prometheus.GaugeVec
+	teamLosses       *prometheus.GaugeVec
+	teamRank         *prometheus.GaugeVec
+	teamMatches      *prometheus.GaugeVec
+	teamGoalDiff     *prometheus.GaugeVec
+	teamXG           *prometheus.GaugeVec
+	teamXGA          *prometheus.GaugeVec
+	teamPPG          *prometheus.GaugeVec
+	teamWinPct       *prometheus.GaugeVec
+	teamGAPerGame    *prometheus.GaugeVec
+	teamXPoints      *prometheus.GaugeVec
+	teamFormPoints   *prometheus.GaugeVec
+	teamPointsHome   *prometheus.GaugeVec
+	teamPointsAway   *prometheus.GaugeVec
+	teamAttendance   *prometheus.GaugeVec
+	teamPossession   *prometheus.GaugeVec
+	teamXA           *prometheus.GaugeVec
+	teamSquadSize    *prometheus.GaugeVec
+	teamAvgAge       *prometheus.GaugeVec
+	teamCleanSheets  *prometheus.GaugeVec
+
+	// Fixture metrics
+	fixtureScheduled *prometheus.GaugeVec
+	fixtureKickoff   *prometheus.GaugeVec
+	matchHomeGoals   *prometheus.GaugeVec
+	matchAwayGoals   *prometheus.GaugeVec
+
+	// Exporter health metrics
+	scrapeSuccess    prometheus.Gauge
+	scrapeDuration   prometheus.Gauge
+	fetchDuration    prometheus.Gauge
+	parseDuration    prometheus.Gauge
+	scrapeBackoff    prometheus.Gauge
+	scrapeInterval   prometheus.Gauge
+	lastScrapeTime   prometheus.Gauge
+	scrapesTotal     *prometheus.CounterVec
+	scrapesSkipped   prometheus.Counter
+	notModifiedTotal prometheus.Counter
+	fetchAttempts    prometheus.Counter
+	fetchFailures    prometheus.Counter
+	challengePages   prometheus.Counter
+	fetchStatus      *prometheus.CounterVec
+	pushErrors       prometheus.Counter
+	buildInfo        *prometheus.GaugeVec
+	parseErrors      *prometheus.CounterVec
+	scrapedPlayers   *prometheus.GaugeVec
+	scrapedTeams     *prometheus.GaugeVec
+	fetchedBytes     *prometheus.GaugeVec
+	scrapedKeepers   *prometheus.GaugeVec
+	sinceSuccess     *freshnessCollector
+}
+
+// newMetrics creates the exporter's collectors and registers them with reg.
+func newMetrics(reg prometheus.Registerer, groups metricGroups) *metrics {
+	m := &metrics{
+		// Player-level metrics
+		topScorer: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{Name: "premier_league_player_goals", Help: "Goals scored by each Premier League player"},
+			playerInfoLabels,
+		),
+		topAssists: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{Name: "premier_league_player_assists", Help: "Assists made by each Premier League player"},
+			playerInfoLabels,
+		),
+		goalInvolvements: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{Name: "premier_league_player_goal_involvements", Help: "Goals plus assists for each Premier League player"},
+			playerLabels,
+		),
+		yellowCards: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{Name: "premier_league_player_yellow_cards", Help: "Yellow cards received by each Premier League player"},
+			playerLabels,
+		),
+		redCards: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{Name: "premier_league_player_red_cards", Help: "Red cards received by each Premier League player"},
+			playerLabels,
+		),
+		playerMinutes: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{Name: "premier_league_player_minutes", Help: "Minutes played by each Premier League player"},
+			playerLabels,
+		),
+		playerAppearances: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{Name: "premier_league_player_appearances", Help: "League appearances by each Premier League player"},
+			playerLabels,
+		),
+		playerStarts: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{Name: "premier_league_player_starts", Help: "League starts by each Premier League player"},
+			playerLabels,
+		),
+		playerAge: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{Name: "premier_league_player_age_years", Help: "Age in whole years of each Premier League player"},
+			playerLabels,
+		),
+		playerXG: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{Name: "premier_league_player_xg", Help: "Expected goals (xG) for each Premier League player"},
+			playerLabels,
+		),
+		playerXA: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{Name: "premier_league_player_xa", Help: "Expected assists (xA) for each Premier League player"},
+			playerLabels,
+		),
+		playerNPXG: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{Name: "premier_league_player_npxg", Help: "Non-penalty expected goals (npxG) for each Premier League player"},
+			playerLabels,
+		),
+		playerNPXGPerShot: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{Name: "premier_league_player_npxg_per_shot", Help: "Non-penalty xG per shot for each Premier League player"},
+			playerLabels,
+		),
+		playerShots: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{Name: "premier_league_player_shots", Help: "Total shots taken by each Premier League player"},
+			playerLabels,
+		),
+		playerShotsOnTarget: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{Name: "premier_league_player_shots_on_target", Help: "Shots on target by each Premier League player"},
+			playerLabels,
+		),
+		playerGoalsPer90: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{Name: "premier_league_player_goals_per90", Help: "Goals per 90 minutes for each Premier League player"},
+			playerLabels,
+		),
+		playerAssistsPer90: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{Name: "premier_league_player_assists_per90", Help: "Assists per 90 minutes for each Premier League player"},
+			playerLabels,
+		),
+		playerPassPct: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{Name: "premier_league_player_pass_completion_pct", Help: "Pass completion percentage for each Premier League player"},
+			playerLabels,
+		),
+		playerTackles: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{Name: "premier_league_player_tackles", Help: "Tackles made by each Premier League player"},
+			playerLabels,
+		),
+		playerInterceptions: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{Name: "premier_league_player_interceptions", Help: "Interceptions made by each Premier League player"},
+			playerLabels,
+		),
+		playerOwnGoals: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{Name: "premier_league_player_own_goals", Help: "Own goals scored by each Premier League player"},
+			playerLabels,
+		),
+		playerKeyPasses: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{Name: "premier_league_player_key_passes", Help: "Passes leading directly to a shot by each Premier League player"},
+			playerLabels,
+		),
+		playerSCA: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{Name: "premier_league_player_sca", Help: "Shot-creating actions by each Premier League player"},
+			playerLabels,
+		),
+		playerProgCarries: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{Name: "premier_league_player_progressive_carries", Help: "Progressive carries by each Premier League player"},
+			playerLabels,
+		),
+		playerProgPasses: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{Name: "premier_league_player_progressive_passes", Help: "Progressive passes by each Premier League player"},
+			playerLabels,
+		),
+		cleanSheets: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{Name: "premier_league_goalkeeper_clean_sheets", Help: "Number of clean sheets by each goalkeeper"},
+			keeperLabels,
+		),
+		gkSaves: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{Name: "premier_league_goalkeeper_saves", Help: "Number of saves by each goalkeeper"},
+			keeperLabels,
+		),
+		gkSavePct: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{Name: "premier_league_goalkeeper_save_pct", Help: "Save percentage of each goalkeeper"},
+			keeperLabels,
+		),
+
+		// Team-level metrics
+		teamPoints:       prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "premier_league_team_points", Help: "Current Premier League points per team"}, teamLabels),
+		teamGoalsFor:     prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "premier_league_team_goals_for", Help: "Total goals scored per team"}, teamLabels),
+		teamGoalsAgainst: prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "premier_league_team_goals_against", Help: "Total goals conceded per team"}, teamLabels),
+		teamWins:         prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "premier_league_team_wins", Help: "Total wins per team"}, teamLabels),
+		teamDraws:        prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "premier_league_team_draws", Help: "Total draws per team"}, teamLabels),
+		teamLosses:       prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "premier_league_team_losses", Help: "Total losses per team"}, teamLabels),
+		teamRank:         prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "premier_league_team_rank", Help: "Current league position per team"}, teamLabels),
+		teamMatches:      prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "premier_league_team_matches_played", Help: "Matches played per team"}, teamLabels),
+		teamGoalDiff:     prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "premier_league_team_goal_diff", Help: "Goal difference per team"}, teamLabels),
+		teamXG:           prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "premier_league_team_xg", Help: "Expected goals per team, from the league standings"}, teamLabels),
+		teamXGA:          prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "premier_league_team_xga", Help: "Expected goals against per team, from the league standings or else the FBref squad stats table"}, teamLabels),
+		teamPPG:          prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "premier_league_team_points_per_game", Help: "Points per game played per team (0 before the first match)"}, teamLabels),
+		teamWinPct:       prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "premier_league_team_win_pct", Help: "Share of games won per team, from 0 to 1 (0 before the first match)"}, teamLabels),
+		teamGAPerGame:    prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "premier_league_team_goals_against_per_game", Help: "Goals conceded per game played per team (0 before the first match)"}, teamLabels),
+		teamXPoints:      prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "premier_league_team_xpoints", Help: "Expected points per team, where FBref publishes them"}, teamLabels),
+		teamFormPoints:   prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "premier_league_team_form_points", Help: "Points from the last five league matches per team (W=3, D=1, L=0)"}, teamLabels),
+		teamPointsHome:   prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "premier_league_team_points_home", Help: "Points won at home per team"}, teamLabels),
+		teamPointsAway:   prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "premier_league_team_points_away", Help: "Points won away per team"}, teamLabels),
+		teamAttendance:   prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "premier_league_team_avg_attendance", Help: "Average home league attendance per team (requires -scrape-fixtures)"}, teamLabels),
+		teamPossession:   prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "premier_league_team_possession_pct", Help: "Average possession per team, in percent"}, teamLabels),
+		teamXA:           prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "premier_league_team_xg_assist", Help: "Expected assists (xA) per team, from the FBref squad stats table"}, teamLabels),
+		teamSquadSize:    prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "premier_league_team_squad_size", Help: "Players used in league matches per team"}, teamLabels),
+		teamAvgAge:       prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "premier_league_team_avg_age", Help: "Average age of the players used per team, weighted by minutes played"}, teamLabels),
+		teamCleanSheets:  prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "premier_league_team_clean_sheets", Help: "Clean sheets per team, summed over its goalkeepers"}, teamLabels),
+
+		// Fixture metrics
+		fixtureScheduled: prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "premier_league_fixture_scheduled", Help: "1 for each upcoming fixture (requires -scrape-fixtures)"}, fixtureLabels),
+		fixtureKickoff:   prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "premier_league_fixture_kickoff_timestamp", Help: "Kickoff time of each upcoming fixture as a Unix timestamp"}, fixtureLabels),
+		matchHomeGoals:   prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "premier_league_match_home_goals", Help: "Goals scored by the home side in each played match (requires -scrape-fixtures)"}, fixtureLabels),
+		matchAwayGoals:   prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "premier_league_match_away_goals", Help: "Goals scored by the away side in each played match (requires -scrape-fixtures)"}, fixtureLabels),
+
+		// Exporter health metrics
+		scrapeSuccess:    prometheus.NewGauge(prometheus.GaugeOpts{Name: "fbref_scrape_success", Help: "Whether the last scrape succeeded (1=success, 0=failure)"}),
+		scrapeDuration:   prometheus.NewGauge(prometheus.GaugeOpts{Name: "fbref_scrape_duration_seconds", Help: "Time taken for the last FBref scrape in seconds"}),
+		fetchDuration:    prometheus.NewGauge(prometheus.GaugeOpts{Name: "fbref_fetch_duration_seconds", Help: "Time the last scrape spent fetching pages, including retries and rate limiting"}),
+		parseDuration:    prometheus.NewGauge(prometheus.GaugeOpts{Name: "fbref_parse_duration_seconds", Help: "Time the last scrape spent parsing pages and writing metrics"}),
+		scrapeBackoff:    prometheus.NewGauge(prometheus.GaugeOpts{Name: "fbref_scrape_backoff_seconds", Help: "Extra wait added to the scrape interval after repeated failed scrapes; 0 when scrapes are succeeding"}),
+		scrapeInterval:   prometheus.NewGauge(prometheus.GaugeOpts{Name: "fbref_scrape_interval_seconds", Help: "Configured time between scheduled scrapes, after the minimum is applied"}),
+		lastScrapeTime:   prometheus.NewGauge(prometheus.GaugeOpts{Name: "fbref_last_scrape_timestamp_seconds", Help: "Unix time of the last successful FBref scrape"}),
+		scrapesTotal:     prometheus.NewCounterVec(prometheus.CounterOpts{Name: "fbref_scrapes_total", Help: "Total FBref scrapes by result"}, []string{"result"}),
+		scrapesSkipped:   prometheus.NewCounter(prometheus.CounterOpts{Name: "fbref_scrape_skipped_total", Help: "Scrapes skipped because the previous one was still running"}),
+		notModifiedTotal: prometheus.NewCounter(prometheus.CounterOpts{Name: "fbref_not_modified_total", Help: "FBref fetches answered with 304 Not Modified"}),
+		fetchAttempts:    prometheus.NewCounter(prometheus.CounterOpts{Name: "fbref_fetch_attempts_total", Help: "HTTP requests made to FBref, retries included"}),
+		fetchFailures:    prometheus.NewCounter(prometheus.CounterOpts{Name: "fbref_fetch_failures_total", Help: "FBref requests that errored or returned a status other than 200 or 304"}),
+		challengePages:   prometheus.NewCounter(prometheus.CounterOpts{Name: "fbref_challenge_pages_total", Help: "FBref responses that were a Cloudflare browser check instead of the page; also counted as fetch failures"}),
+		fetchStatus:      prometheus.NewCounterVec(prometheus.CounterOpts{Name: "fbref_fetch_status_total", Help: "FBref responses by HTTP status code; code=\"error\" when no response was received"}, []string{"code"}),
+		pushErrors:       prometheus.NewCounter(prometheus.CounterOpts{Name: "fbref_push_errors_total", Help: "Failed attempts to push metrics to the Pushgateway"}),
+		buildInfo:        prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "fbref_exporter_build_info", Help: "Exporter build information; the value is always 1"}, []string{"version", "commit", "goversion"}),
+		parseErrors:      prometheus.NewCounterVec(prometheus.CounterOpts{Name: "fbref_parse_errors_total", Help: "Non-empty FBref cells that failed to parse, by table"}, []string{"table"}),
+		scrapedPlayers:   prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "fbref_scraped_players", Help: "Players parsed in the last scrape, by competition (0 when it failed)"}, []string{"competition"}),
+		scrapedTeams:     prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "fbref_scraped_teams", Help: "Teams parsed in the last scrape, by competition (0 when it failed)"}, []string{"competition"}),
+		scrapedKeepers:   prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "fbref_scraped_goalkeepers", Help: "Goalkeepers parsed in the last scrape, by competition (0 when it failed)"}, []string{"competition"}),
+		fetchedBytes:     prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "fbref_fetched_bytes", Help: "Size of the last stats page fetched, comments included, by competition; a real page is around 2MB, a challenge or error page a few KB"}, []string{"competition"}),
+		sinceSuccess:     newFreshnessCollector(time.Now()),
+	}
+
+	// Groups left out of -collectors are never registered, so they don't
+	// show up on /metrics at all.
+	if groups.players {
+		reg.MustRegister(m.topScorer, m.topAssists, m.goalInvolvements, m.yellowCards, m.redCards, m.playerMinutes, m.playerAppearances, m.playerStarts, m.playerAge, m.playerXG, m.playerXA, m.playerNPXG, m.playerNPXGPerShot, m.playerShots, m.playerShotsOnTarget, m.playerGoalsPer90, m.playerAssistsPer90, m.playerPassPct, m.playerTackles, m.playerInterceptions, m.playerOwnGoals, m.playerKeyPasses, m.playerSCA, m.playerProgCarries, m.playerProgPasses)
+	}
+	if groups.goalkeepers {
+		reg.MustRegister(m.cleanSheets, m.gkSaves, m.gkSavePct)
+	}
+	if groups.teams {
+		reg.MustRegister(m.teamPoints, m.teamGoalsFor, m.teamGoalsAgainst, m.teamWins, m.teamDraws, m.teamLosses, m.teamRank, m.teamMatches, m.teamGoalDiff, m.teamXG, m.teamXGA, m.teamPPG, m.teamWinPct, m.teamGAPerGame, m.teamXPoints, m.teamFormPoints, m.teamPointsHome, m.teamPointsAway, m.teamAttendance, m.teamPossession, m.teamXA, m.teamSquadSize, m.teamAvgAge, m.teamCleanSheets)
+	}
+	reg.MustRegister(m.fixtureScheduled, m.fixtureKickoff, m.matchHomeGoals, m.matchAwayGoals)
+	reg.MustRegister(m.scrapeSuccess, m.scrapeDuration, m.fetchDuration, m.parseDuration, m.scrapeBackoff, m.scrapeInterval, m.lastScrapeTime, m.scrapesTotal, m.scrapesSkipped, m.notModifiedTotal, m.fetchAttempts, m.fetchFailures, m.challengePages, m.fetchStatus, m.pushErrors, m.buildInfo, m.parseErrors, m.scrapedPlayers, m.scrapedTeams, m.scrapedKeepers, m.fetchedBytes, m.sinceSuccess)
+
+	// Expose both results from the start so rate() works before the first failure.
+	m.scrapesTotal.WithLabelValues("success")
+	m.scrapesTotal.WithLabelValues("failure")
+	for _, table := range []string{tablePlayer, tableTeam, tableGoalkeeper, tableFixture} {
+		m.parseErrors.WithLabelValues(table)
+	}
+	return m
+}
+
+// freshnessCollector reports fbref_seconds_since_last_success, computed when
+// /metrics is scraped so alert rules don't need time() arithmetic. Until the
+// first success it counts from when the exporter started.
+type freshnessCollector struct {
+	desc *prometheus.Desc
+	last atomic.Int64 // Unix nanoseconds
+}
+
+func newFreshnessCollector(start time.Time) *freshnessCollector {
+	c := &freshnessCollector{
+		desc: prometheus.NewDesc("fbref_seconds_since_last_success", "Seconds since the last successful FBref scrape (or since startup, before the first)", nil, nil),
+	}
+	c.last.Store(start.UnixNano())
+	return c
+}
+
+// succeeded records t as the time of the latest successful scrape.
+func (c *freshnessCollector) succeeded(t time.Time) { c.last.Store(t.UnixNano()) }
+
+func (c *freshnessCollector) Describe(ch chan<- *prometheus.Desc) { ch <- c.desc }
+
+func (c *freshnessCollector) Collect(ch chan<- prometheus.Metric) {
+	age := time.Since(time.Unix(0, c.last.Load())).Seconds()
+	ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, age)
+}
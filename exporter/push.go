@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+)
+
+// --------------------- Pushgateway ---------------------
+
+// pushJob is the Pushgateway job name the exporter pushes under.
+const pushJob = "fbref_exporter"
+
+// pushTimeout bounds one push, retries included.
+const pushTimeout = 30 * time.Second
+
+// gatewayPusher pushes the gathered metrics to a Pushgateway after each scrape,
+// for environments where Prometheus can't scrape the exporter directly.
+type gatewayPusher struct {
+	pusher      *push.Pusher
+	maxAttempts int
+	errors      prometheus.Counter
+}
+
+func newGatewayPusher(url string, gatherer prometheus.Gatherer, maxAttempts int, errors prometheus.Counter) *gatewayPusher {
+	return &gatewayPusher{pusher: push.New(url, pushJob).Gatherer(gatherer), maxAttempts: maxAttempts, errors: errors}
+}
+
+// push sends the current metrics, retrying with the same backoff as page fetches.
+func (p *gatewayPusher) push(ctx context.Context) error {
+	var err error
+	for attempt := 1; attempt <= p.maxAttempts; attempt++ {
+		if err = p.pusher.PushContext(ctx); err == nil {
+			return nil
+		}
+		p.errors.Inc()
+		log.Printf("[WARN] Push attempt %d failed: %v", attempt, err)
+		if attempt < p.maxAttempts {
+			if err := sleepCtx(ctx, backoff(attempt)); err != nil {
+				return err
+			}
+		}
+	}
+	return fmt.Errorf("pushing metrics after %d attempts: %w", p.maxAttempts, err)
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// --------------------- Selectors ---------------------
+
+// dataStats maps each logical field the parser reads to the data-stat attribute
+// FBref currently uses for it. FBref renames these now and then; -selectors-file
+// lets operators follow a rename without a rebuild. The _alt entries are older
+// or alternative names tried when the primary one is missing from a table.
+var dataStats = map[string]string{
+	// Player tables
+	"player_name":                "player",
+	"player_team":                "team",
+	"player_position":            "position",
+	"player_nationality":         "nationality",
+	"player_age":                 "age",
+	"player_goals":               "goals",
+	"player_assists":             "assists",
+	"player_yellow_cards":        "cards_yellow",
+	"player_red_cards":           "cards_red",
+	"player_minutes":             "minutes",
+	"player_appearances":         "games",
+	"player_starts":              "games_starts",
+	"player_xg":                  "xg",
+	"player_xa":                  "xg_assist",
+	"player_npxg":                "npxg",
+	"player_npxg_per_shot":       "npxg_per_shot",
+	"player_goals_per90":         "goals_per90",
+	"player_assists_per90":       "assists_per90",
+	"player_shots":               "shots",
+	"player_shots_on_target":     "shots_on_target",
+	"player_pass_pct":            "passes_pct",
+	"player_key_passes":          "assisted_shots",
+	"player_sca":                 "sca",
+	"player_progressive_carries": "progressive_carries",
+	"player_progressive_passes":  "progressive_passes",
+	"player_tackles":             "tackles",
+	"player_interceptions":       "interceptions",
+	"player_own_goals":           "own_goals",
+	"player_league":              "comp_level",
+
+	// Goalkeeper tables
+	"gk_clean_sheets": "clean_sheets",
+	"gk_saves":        "gk_saves",
+	"gk_save_pct":     "gk_save_pct",
+
+	// Standings and squad tables
+	"team_name":            "team",
+	"team_rank":            "rank",
+	"team_points":          "points",
+	"team_games":           "games",
+	"team_wins":            "wins",
+	"team_draws":           "draws",
+	"team_losses":          "losses",
+	"team_goals_for":       "goals_for",
+	"team_goals_against":   "goals_against",
+	"team_goal_diff":       "goal_diff",
+	"team_xg":              "xg_for",
+	"team_xg_alt":          "xg",
+	"team_xga":             "xg_against",
+	"team_xpoints":         "xg_points",
+	"team_last_5":          "last_5",
+	"team_points_home":     "home_points",
+	"team_points_away":     "away_points",
+	"team_points_home_alt": "points_home",
+	"team_points_away_alt": "points_away",
+	"team_possession":      "possession",
+	"team_xa":              "xg_assist",
+	"team_players_used":    "players_used",
+	"team_avg_age":         "avg_age",
+
+	// Schedule table
+	"fixture_date":       "date",
+	"fixture_start_time": "start_time",
+	"fixture_home_team":  "home_team",
+	"fixture_away_team":  "away_team",
+	"fixture_score":      "score",
+	"fixture_attendance": "attendance",
+}
+
+// dataStat returns the data-stat attribute for a logical field.
+func dataStat(field string) string {
+	if v, ok := dataStats[field]; ok {
+		return v
+	}
+	panic("unknown selector field " + field)
+}
+
+// td and th return the selector for the data cell or header cell of a field.
+func td(field string) string { return "td[data-stat='" + dataStat(field) + "']" }
+func th(field string) string { return "th[data-stat='" + dataStat(field) + "']" }
+
+// loadSelectors overrides entries of dataStats from a JSON object of logical
+// field → data-stat in path. Unknown fields are rejected so a typo doesn't
+// silently leave the old selector in place.
+func loadSelectors(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading selectors: %w", err)
+	}
+	var overrides map[string]string
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return fmt.Errorf("parsing selectors %s: %w", path, err)
+	}
+	var unknown []string
+	for field := range overrides {
+		if _, ok := dataStats[field]; !ok {
+			unknown = append(unknown, field)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown selector fields in %s: %s", path, strings.Join(unknown, ", "))
+	}
+	for field, stat := range overrides {
+		dataStats[field] = strings.TrimSpace(stat)
+	}
+	return nil
+}
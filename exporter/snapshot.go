@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// --------------------- Snapshot Persistence ---------------------
+
+// snapshotFile is the on-disk form of the last successful scrape, written with
+// -snapshot-file so a restarted exporter has metrics before its first scrape.
+type snapshotFile struct {
+	Season       string            `json:"season"`
+	SavedAt      time.Time         `json:"saved_at"`
+	Competitions map[string]*Stats `json:"competitions"`
+}
+
+// saveSnapshot writes the current snapshot to path.
+func (s *scraper) saveSnapshot(path string) error {
+	s.snapshotMu.RLock()
+	data, err := json.Marshal(snapshotFile{Season: s.season, SavedAt: s.updatedAt, Competitions: s.snapshot})
+	s.snapshotMu.RUnlock()
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data)
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames it
+// into place, so a crash mid-write never leaves a truncated file behind and
+// readers only ever see a complete one.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// loadSnapshot pre-populates the gauges from a snapshot saved by an earlier
+// run. A missing file is not an error. A snapshot older than the scrape
+// interval is still loaded, with a warning; one for a different season is not.
+func (s *scraper) loadSnapshot(path string) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var snap snapshotFile
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return fmt.Errorf("parsing snapshot %s: %w", path, err)
+	}
+	if snap.Season != s.season {
+		log.Printf("[WARN] Ignoring snapshot %s for season %s (scraping %s)", path, snap.Season, s.season)
+		return nil
+	}
+	if age := time.Since(snap.SavedAt); age > s.interval {
+		log.Printf("[WARN] Snapshot %s is %s old, older than the scrape interval; loading it anyway", path, age.Round(time.Second))
+	}
+
+	restored := 0
+	for _, c := range s.competitions {
+		stats, ok := snap.Competitions[c.Slug]
+		if !ok || stats == nil {
+			continue
+		}
+		log.Printf("[INFO] Restoring %s from snapshot saved %s", c.Slug, snap.SavedAt.Format(time.RFC3339))
+		s.writeSeries(c, stats, snap.SavedAt)
+		restored++
+	}
+	if restored > 0 {
+		s.m.lastScrapeTime.Set(float64(snap.SavedAt.Unix()))
+		s.m.sinceSuccess.succeeded(snap.SavedAt)
+	}
+	return nil
+}
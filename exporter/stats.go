@@ -0,0 +1,734 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/text/unicode/norm"
+)
+
+// --------------------- Parsed Stats ---------------------
+
+// PlayerStat holds everything parsed for one player across the FBref player tables.
+// Fields are nil when the table that carries them was not present for this player.
+type PlayerStat struct {
+	Player        string   `json:"player"`
+	Team          string   `json:"team"`
+	Position      string   `json:"position"`
+	Nationality   string   `json:"nationality"`
+	League        string   `json:"league,omitempty"`
+	Goals         *float64 `json:"goals,omitempty"`
+	Assists       *float64 `json:"assists,omitempty"`
+	Involvements  *float64 `json:"goal_involvements,omitempty"`
+	YellowCards   *float64 `json:"yellow_cards,omitempty"`
+	RedCards      *float64 `json:"red_cards,omitempty"`
+	Minutes       *float64 `json:"minutes,omitempty"`
+	Appearances   *float64 `json:"appearances,omitempty"`
+	Starts        *float64 `json:"starts,omitempty"`
+	AgeYears      *float64 `json:"age_years,omitempty"`
+	XG            *float64 `json:"xg,omitempty"`
+	XA            *float64 `json:"xa,omitempty"`
+	NPXG          *float64 `json:"npxg,omitempty"`
+	NPXGPerShot   *float64 `json:"npxg_per_shot,omitempty"`
+	Shots         *float64 `json:"shots,omitempty"`
+	ShotsOnTarget *float64 `json:"shots_on_target,omitempty"`
+	GoalsPer90    *float64 `json:"goals_per90,omitempty"`
+	AssistsPer90  *float64 `json:"assists_per90,omitempty"`
+	PassPct       *float64 `json:"pass_completion_pct,omitempty"`
+	Tackles       *float64 `json:"tackles,omitempty"`
+	Interceptions *float64 `json:"interceptions,omitempty"`
+	OwnGoals      *float64 `json:"own_goals,omitempty"`
+	KeyPasses     *float64 `json:"key_passes,omitempty"`
+	SCA           *float64 `json:"sca,omitempty"`
+	ProgCarries   *float64 `json:"progressive_carries,omitempty"`
+	ProgPasses    *float64 `json:"progressive_passes,omitempty"`
+}
+
+// GoalkeeperStat holds the keeper-specific numbers for one goalkeeper.
+type GoalkeeperStat struct {
+	Player      string   `json:"player"`
+	Team        string   `json:"team"`
+	CleanSheets *float64 `json:"clean_sheets,omitempty"`
+	Saves       *float64 `json:"saves,omitempty"`
+	SavePct     *float64 `json:"save_pct,omitempty"`
+}
+
+// TeamStat holds one row of the league standings.
+type TeamStat struct {
+	Team                string   `json:"team"`
+	Rank                float64  `json:"rank"`
+	Points              float64  `json:"points"`
+	Matches             float64  `json:"matches"`
+	Wins                float64  `json:"wins"`
+	Draws               float64  `json:"draws"`
+	Losses              float64  `json:"losses"`
+	GoalsFor            float64  `json:"goals_for"`
+	GoalsAgainst        float64  `json:"goals_against"`
+	GoalDiff            float64  `json:"goal_diff"`
+	XG                  *float64 `json:"xg,omitempty"`
+	XGA                 *float64 `json:"xga,omitempty"`
+	PointsPerGame       float64  `json:"points_per_game"`
+	WinPct              float64  `json:"win_pct"`
+	GoalsAgainstPerGame float64  `json:"goals_against_per_game"`
+	XPoints             *float64 `json:"xpoints,omitempty"`
+	FormPoints          *float64 `json:"form_points,omitempty"`
+	PointsHome          *float64 `json:"points_home,omitempty"`
+	PointsAway          *float64 `json:"points_away,omitempty"`
+	AvgAttendance       *float64 `json:"avg_attendance,omitempty"`
+	Possession          *float64 `json:"possession_pct,omitempty"`
+	XA                  *float64 `json:"xa,omitempty"`
+	SquadSize           *float64 `json:"squad_size,omitempty"`
+	AvgAge              *float64 `json:"avg_age,omitempty"`
+	CleanSheets         *float64 `json:"clean_sheets,omitempty"`
+}
+
+// Stats is the result of parsing one FBref page, independent of any metrics registry.
+type Stats struct {
+	Players     []PlayerStat     `json:"players"`
+	Goalkeepers []GoalkeeperStat `json:"goalkeepers"`
+	Teams       []TeamStat       `json:"teams"`
+	Fixtures    []Fixture        `json:"fixtures,omitempty"`
+	Results     []Result         `json:"results,omitempty"`
+
+	// ParseErrors counts non-empty cells that failed to parse, keyed by table
+	// group. Blank cells are expected and are not counted.
+	ParseErrors map[string]int `json:"-"`
+
+	// AdvancedTeamTable reports whether the squad stats table that team
+	// possession, xA, squad size and average age come from was on the page.
+	AdvancedTeamTable bool `json:"-"`
+}
+
+// Table groups used to attribute parse errors.
+const (
+	tablePlayer     = "player"
+	tableTeam       = "team"
+	tableGoalkeeper = "goalkeeper"
+)
+
+var errNoStats = errors.New("no player or team tables found")
+
+// goalDiffReplacer strips the explicit plus sign and swaps the unicode minus FBref
+// uses for negative goal difference ("+12", "−7") so ParseFloat accepts the value.
+var goalDiffReplacer = strings.NewReplacer("+", "", "\u2212", "-")
+
+// --------------------- Parsing ---------------------
+
+// statsBuilder merges rows for the same player or team that arrive from different tables.
+type statsBuilder struct {
+	stats       Stats
+	players     map[string]int
+	goalkeepers map[string]int
+
+	// teamExtras collects team values from tables other than the standings.
+	// They are merged into Teams once every table has been seen, since those
+	// tables may come before or after the standings.
+	teamExtras map[string]*teamExtra
+
+	// maxRows caps the rows read from each table body; 0 means no cap.
+	maxRows int
+
+	// groups selects the tables parsed; the others are skipped entirely.
+	groups metricGroups
+}
+
+// rows returns the body rows of the tables in d, at most b.maxRows from each,
+// so a pathological page can't make the parser walk an unbounded table.
+func (b *statsBuilder) rows(d *goquery.Selection) *goquery.Selection {
+	if b.maxRows <= 0 {
+		return d.Find("tbody tr")
+	}
+	out := d.Find("tbody tr").Slice(0, 0)
+	d.Find("tbody").Each(func(_ int, body *goquery.Selection) {
+		trs := body.Find("tr")
+		if n := trs.Length(); n > b.maxRows {
+			log.Printf("[WARN] Table has %d rows, reading only the first %d (-max-rows-per-table)", n, b.maxRows)
+			trs = trs.Slice(0, b.maxRows)
+		}
+		out = out.AddSelection(trs)
+	})
+	return out
+}
+
+// teamExtra holds the team values that come from outside the standings table.
+type teamExtra struct {
+	xga                    *float64
+	pointsHome, pointsAway *float64
+	possession             *float64
+	xa                     *float64
+	squadSize, avgAge      *float64
+	cleanSheets            *float64
+}
+
+func (b *statsBuilder) teamExtra(team string) *teamExtra {
+	e, ok := b.teamExtras[team]
+	if !ok {
+		e = &teamExtra{}
+		b.teamExtras[team] = e
+	}
+	return e
+}
+
+func (b *statsBuilder) player(name, team string) *PlayerStat {
+	key := name + "|" + team
+	i, ok := b.players[key]
+	if !ok {
+		i = len(b.stats.Players)
+		b.players[key] = i
+		b.stats.Players = append(b.stats.Players, PlayerStat{Player: name, Team: team})
+	}
+	return &b.stats.Players[i]
+}
+
+func (b *statsBuilder) goalkeeper(name, team string) *GoalkeeperStat {
+	key := name + "|" + team
+	i, ok := b.goalkeepers[key]
+	if !ok {
+		i = len(b.stats.Goalkeepers)
+		b.goalkeepers[key] = i
+		b.stats.Goalkeepers = append(b.stats.Goalkeepers, GoalkeeperStat{Player: name, Team: team})
+	}
+	return &b.stats.Goalkeepers[i]
+}
+
+// cellText returns the trimmed text of the data cell for a logical field.
+func cellText(s *goquery.Selection, field string) string {
+	return strings.TrimSpace(s.Find(td(field)).Text())
+}
+
+// cleanStat strips whitespace and thousands separators from a numeric cell.
+func cleanStat(raw string) string {
+	return strings.TrimSpace(strings.ReplaceAll(raw, ",", ""))
+}
+
+// isBlankStat reports whether a cleaned cell is one of FBref's "no value"
+// placeholders: empty, or a lone hyphen or dash.
+func isBlankStat(raw string) bool {
+	switch raw {
+	case "", "-", "\u2013", "\u2014":
+		return true
+	}
+	return false
+}
+
+// parseStat parses an FBref numeric cell such as "1,234" or "7.3". It reports
+// false when the cell holds no value (blank or a dash placeholder) or cannot be
+// parsed, leaving callers to decide whether that means 0 or no series at all.
+func parseStat(raw string) (float64, bool) {
+	raw = cleanStat(raw)
+	if isBlankStat(raw) {
+		return 0, false
+	}
+	v, err := strconv.ParseFloat(raw, 64)
+	return v, err == nil
+}
+
+// parse is parseStat that also records unparseable, non-placeholder text against
+// table so markup regressions show up in the metrics.
+func (b *statsBuilder) parse(table, raw string) (float64, bool) {
+	v, ok := parseStat(raw)
+	if !ok && !isBlankStat(cleanStat(raw)) {
+		b.stats.ParseErrors[table]++
+	}
+	return v, ok
+}
+
+// value and optional hold the policy for cells without a value, which are
+// common early in the season: every numeric cell is read through one of them,
+// except player age, which is split out of its cell first and follows value.
+//
+// value parses a counting cell (points, games, goals, cards). A blank or
+// dashed one means nothing has happened yet and counts as 0.
+func (b *statsBuilder) value(table string, s *goquery.Selection, field string) float64 {
+	v, _ := b.parse(table, cellText(s, field))
+	return v
+}
+
+// optional parses a decimal or advanced cell (xG, rates, percentages) and
+// returns nil when it has no value, so no series is written: a 0 there would
+// be a claim the page doesn't make.
+func (b *statsBuilder) optional(table string, s *goquery.Selection, field string) *float64 {
+	if v, ok := b.parse(table, cellText(s, field)); ok {
+		return &v
+	}
+	return nil
+}
+
+// setOptional sets *dst through optional, but only when the row has the
+// field's cell. It is for columns that some, not all, of the tables feeding
+// one value carry, so a later table without the column doesn't clear it.
+func (b *statsBuilder) setOptional(dst **float64, table string, s *goquery.Selection, field string) {
+	if s.Find(td(field)).Length() > 0 {
+		*dst = b.optional(table, s, field)
+	}
+}
+
+// multipleTeams is the team label for a player's combined row after a
+// mid-season move.
+const multipleTeams = "Multiple"
+
+// combinedTeamsPattern matches FBref's team cell on a combined row for players
+// who appeared for more than one club, e.g. "2 Teams".
+var combinedTeamsPattern = regexp.MustCompile(`^\d+ Teams?$`)
+
+// playerRow returns the player name and normalized team of a player table row.
+// Combined rows for transferred players are reported under multipleTeams.
+func playerRow(s *goquery.Selection) (player, team string) {
+	team = normalizeTeamName(cellText(s, "player_team"))
+	if combinedTeamsPattern.MatchString(team) {
+		team = multipleTeams
+	}
+	return sanitizeLabel(cellText(s, "player_name")), team
+}
+
+// normalizeLabels enables sanitizeLabel; set from -normalize-labels.
+var normalizeLabels bool
+
+// sanitizeLabel puts a player or team name into Unicode NFC and trims it, so
+// a name FBref serves precomposed on one page and with combining accents on
+// another maps to one series. Names pass through untouched unless
+// normalizeLabels is set.
+func sanitizeLabel(name string) string {
+	if !normalizeLabels {
+		return name
+	}
+	return strings.TrimSpace(norm.NFC.String(name))
+}
+
+// dropRedundantCombined resolves players who moved clubs mid-season. We prefer
+// the per-team rows FBref gives when a table splits the player by club, since
+// those keep stats attributed to the right team; the combined "N Teams" row is
+// then dropped so the player isn't counted twice. Only when no per-team rows
+// exist is the combined row kept, under team="Multiple".
+func dropRedundantCombined[T any](rows []T, key func(T) (player, team string)) []T {
+	split := map[string]bool{}
+	for _, r := range rows {
+		if player, team := key(r); team != multipleTeams {
+			split[player] = true
+		}
+	}
+	kept := rows[:0]
+	for _, r := range rows {
+		if player, team := key(r); team != multipleTeams || !split[player] {
+			kept = append(kept, r)
+		}
+	}
+	return kept
+}
+
+// unknownLabel stands in for player attributes FBref left blank.
+const unknownLabel = "unknown"
+
+// nationalityCode extracts the country code from FBref's nationality cell, which
+// renders a flag code and the code itself, e.g. "eng ENG".
+func nationalityCode(raw string) string {
+	fields := strings.Fields(raw)
+	for i := len(fields) - 1; i >= 0; i-- {
+		if f := fields[i]; f == strings.ToUpper(f) && strings.ToUpper(f) != strings.ToLower(f) {
+			return f
+		}
+	}
+	return unknownLabel
+}
+
+// leagueName drops the flag code FBref puts before the league in the big-5
+// page's competition cell, e.g. "eng Premier League". Pages without the
+// column give "".
+func leagueName(raw string) string {
+	code, name, ok := strings.Cut(raw, " ")
+	if ok && code == strings.ToLower(code) {
+		return strings.TrimSpace(name)
+	}
+	return raw
+}
+
+// orUnknown returns v, or unknownLabel when v is blank.
+func orUnknown(v string) string {
+	if v == "" {
+		return unknownLabel
+	}
+	return v
+}
+
+func ptr(v float64) *float64 { return &v }
+
+// formPoints scores FBref's "Last 5" cell, which renders each result as a
+// linked W, D or L, oldest first. Anything else in the cell is ignored. It
+// reports false when the cell holds no results at all.
+func formPoints(raw string) (float64, bool) {
+	var results []rune
+	for _, r := range raw {
+		if r == 'W' || r == 'D' || r == 'L' {
+			results = append(results, r)
+		}
+	}
+	if len(results) == 0 {
+		return 0, false
+	}
+	if len(results) > 5 {
+		results = results[len(results)-5:]
+	}
+	var points float64
+	for _, r := range results {
+		switch r {
+		case 'W':
+			points += 3
+		case 'D':
+			points++
+		}
+	}
+	return points, true
+}
+
+// advancedTeamTable reports whether d is the squad standard stats table: one
+// row per team like the standings, but with squad columns instead of points.
+func advancedTeamTable(d *goquery.Selection) bool {
+	if d.Find(th("team_name")).Length() == 0 || d.Find(td("team_points")).Length() > 0 {
+		return false
+	}
+	for _, field := range []string{"team_possession", "team_xa", "team_players_used", "team_avg_age"} {
+		if d.Find(td(field)).Length() > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// tables lists every table in docs. Each is matched on its own: the live page
+// holds the standings next to other tables with team rows, and one table's
+// columns must not make another's rows count.
+func tables(docs []*goquery.Document) []*goquery.Selection {
+	var out []*goquery.Selection
+	for _, d := range docs {
+		d.Find("table").Each(func(_ int, t *goquery.Selection) {
+			out = append(out, t)
+		})
+	}
+	return out
+}
+
+// parseStats walks the page and its commented-out tables and collects every
+// player, goalkeeper and team row it recognises.
+func parseStats(docs []*goquery.Document, maxRows int, groups metricGroups) (*Stats, error) {
+	b := &statsBuilder{
+		maxRows:     maxRows,
+		groups:      groups,
+		stats:       Stats{ParseErrors: map[string]int{}},
+		players:     map[string]int{},
+		goalkeepers: map[string]int{},
+		teamExtras:  map[string]*teamExtra{},
+	}
+
+	for _, tbl := range tables(docs) {
+		// --- Player stats (standard table) ---
+		// The shooting table has a goals column too, but no assists; requiring
+		// both keeps it from overwriting the standard table's fields.
+		if b.groups.players && tbl.Find(th("player_name")).Length() > 0 && tbl.Find(td("player_goals")).Length() > 0 &&
+			tbl.Find(td("player_assists")).Length() > 0 {
+			b.rows(tbl).Each(func(_ int, s *goquery.Selection) {
+				player, team := playerRow(s)
+				if player == "" || team == "" {
+					return
+				}
+				p := b.player(player, team)
+				p.Position = orUnknown(cellText(s, "player_position"))
+				p.Nationality = nationalityCode(cellText(s, "player_nationality"))
+				p.League = leagueName(cellText(s, "player_league"))
+				goals, assists := b.value(tablePlayer, s, "player_goals"), b.value(tablePlayer, s, "player_assists")
+				p.Goals, p.Assists = ptr(goals), ptr(assists)
+				p.Involvements = ptr(goals + assists)
+				// Blank card cells fail to parse and fall through as 0, which is what we want.
+				p.YellowCards = ptr(b.value(tablePlayer, s, "player_yellow_cards"))
+				p.RedCards = ptr(b.value(tablePlayer, s, "player_red_cards"))
+				// FBref formats minutes with thousands separators, e.g. "1,530".
+				p.Minutes = ptr(b.value(tablePlayer, s, "player_minutes"))
+				p.Appearances = ptr(b.value(tablePlayer, s, "player_appearances"))
+				p.Starts = ptr(b.value(tablePlayer, s, "player_starts"))
+				// Age is rendered as years-days, e.g. "27-164"; only the years matter
+				// here. Like the counting columns, a blank age reads as 0.
+				years, _, _ := strings.Cut(cellText(s, "player_age"), "-")
+				age, _ := b.parse(tablePlayer, years)
+				p.AgeYears = ptr(age)
+				// Aggregate and repeated header rows carry no xG/xA at all; leave them out.
+				p.XG = b.optional(tablePlayer, s, "player_xg")
+				p.XA = b.optional(tablePlayer, s, "player_xa")
+				// FBref leaves per-90 rates blank below 30 minutes played; no series then.
+				p.GoalsPer90 = b.optional(tablePlayer, s, "player_goals_per90")
+				p.AssistsPer90 = b.optional(tablePlayer, s, "player_assists_per90")
+			})
+		}
+
+		// --- Player shooting (separate commented-out table from goals) ---
+		if b.groups.players && tbl.Find(th("player_name")).Length() > 0 && tbl.Find(td("player_shots")).Length() > 0 {
+			b.rows(tbl).Each(func(_ int, s *goquery.Selection) {
+				player, team := playerRow(s)
+				if player == "" || team == "" {
+					return
+				}
+				p := b.player(player, team)
+				p.Shots = ptr(b.value(tablePlayer, s, "player_shots"))
+				p.ShotsOnTarget = ptr(b.value(tablePlayer, s, "player_shots_on_target"))
+			})
+		}
+
+		// --- Player non-penalty xG (standard table; shooting adds npxG per shot) ---
+		if b.groups.players && tbl.Find(th("player_name")).Length() > 0 && tbl.Find(td("player_npxg")).Length() > 0 {
+			b.rows(tbl).Each(func(_ int, s *goquery.Selection) {
+				player, team := playerRow(s)
+				if player == "" || team == "" {
+					return
+				}
+				p := b.player(player, team)
+				p.NPXG = b.optional(tablePlayer, s, "player_npxg")
+				// Only the shooting table has npxG per shot; it is blank for players
+				// without a shot.
+				b.setOptional(&p.NPXGPerShot, tablePlayer, s, "player_npxg_per_shot")
+			})
+		}
+
+		// --- Player passing (commented-out table) ---
+		if b.groups.players && tbl.Find(th("player_name")).Length() > 0 && tbl.Find(td("player_pass_pct")).Length() > 0 {
+			b.rows(tbl).Each(func(_ int, s *goquery.Selection) {
+				player, team := playerRow(s)
+				if player == "" || team == "" {
+					return
+				}
+				// Players who haven't attempted a pass have no percentage.
+				b.player(player, team).PassPct = b.optional(tablePlayer, s, "player_pass_pct")
+			})
+		}
+
+		// --- Player key passes (passing table, which FBref calls assisted shots) ---
+		if b.groups.players && tbl.Find(th("player_name")).Length() > 0 && tbl.Find(td("player_key_passes")).Length() > 0 {
+			b.rows(tbl).Each(func(_ int, s *goquery.Selection) {
+				player, team := playerRow(s)
+				if player == "" || team == "" {
+					return
+				}
+				b.player(player, team).KeyPasses = b.optional(tablePlayer, s, "player_key_passes")
+			})
+		}
+
+		// --- Player shot-creating actions (goal and shot creation table) ---
+		if b.groups.players && tbl.Find(th("player_name")).Length() > 0 && tbl.Find(td("player_sca")).Length() > 0 {
+			b.rows(tbl).Each(func(_ int, s *goquery.Selection) {
+				player, team := playerRow(s)
+				if player == "" || team == "" {
+					return
+				}
+				b.player(player, team).SCA = b.optional(tablePlayer, s, "player_sca")
+			})
+		}
+
+		// --- Player progressive carries (possession table) ---
+		if b.groups.players && tbl.Find(th("player_name")).Length() > 0 && tbl.Find(td("player_progressive_carries")).Length() > 0 {
+			b.rows(tbl).Each(func(_ int, s *goquery.Selection) {
+				player, team := playerRow(s)
+				if player == "" || team == "" {
+					return
+				}
+				b.player(player, team).ProgCarries = b.optional(tablePlayer, s, "player_progressive_carries")
+			})
+		}
+
+		// --- Player progressive passes (passing table) ---
+		if b.groups.players && tbl.Find(th("player_name")).Length() > 0 && tbl.Find(td("player_progressive_passes")).Length() > 0 {
+			b.rows(tbl).Each(func(_ int, s *goquery.Selection) {
+				player, team := playerRow(s)
+				if player == "" || team == "" {
+					return
+				}
+				b.player(player, team).ProgPasses = b.optional(tablePlayer, s, "player_progressive_passes")
+			})
+		}
+
+		// --- Player defensive actions (commented-out table) ---
+		if b.groups.players && tbl.Find(th("player_name")).Length() > 0 && tbl.Find(td("player_tackles")).Length() > 0 {
+			b.rows(tbl).Each(func(_ int, s *goquery.Selection) {
+				player, team := playerRow(s)
+				if player == "" || team == "" {
+					return
+				}
+				p := b.player(player, team)
+				p.Tackles = ptr(b.value(tablePlayer, s, "player_tackles"))
+				p.Interceptions = ptr(b.value(tablePlayer, s, "player_interceptions"))
+			})
+		}
+
+		// --- Player miscellaneous stats (commented-out table) ---
+		if b.groups.players && tbl.Find(th("player_name")).Length() > 0 && tbl.Find(td("player_own_goals")).Length() > 0 {
+			b.rows(tbl).Each(func(_ int, s *goquery.Selection) {
+				player, team := playerRow(s)
+				if player == "" || team == "" {
+					return
+				}
+				b.player(player, team).OwnGoals = ptr(b.value(tablePlayer, s, "player_own_goals"))
+			})
+		}
+
+		// --- Goalkeeper clean sheets, also summed per team ---
+		if (b.groups.goalkeepers || b.groups.teams) && tbl.Find(th("player_name")).Length() > 0 && tbl.Find(td("gk_clean_sheets")).Length() > 0 {
+			b.rows(tbl).Each(func(_ int, s *goquery.Selection) {
+				player, team := playerRow(s)
+				if player == "" || team == "" {
+					return
+				}
+				cs := b.value(tableGoalkeeper, s, "gk_clean_sheets")
+				if b.groups.goalkeepers {
+					b.goalkeeper(player, team).CleanSheets = ptr(cs)
+				}
+				// A combined row for a keeper who moved can't be split between
+				// clubs; the per-club rows carry those clean sheets instead.
+				if b.groups.teams && team != multipleTeams {
+					e := b.teamExtra(team)
+					if e.cleanSheets == nil {
+						e.cleanSheets = ptr(0)
+					}
+					*e.cleanSheets += cs
+				}
+			})
+		}
+
+		// --- Goalkeeper saves ---
+		if b.groups.goalkeepers && tbl.Find(th("player_name")).Length() > 0 && tbl.Find(td("gk_saves")).Length() > 0 {
+			b.rows(tbl).Each(func(_ int, s *goquery.Selection) {
+				player, team := playerRow(s)
+				if player == "" || team == "" {
+					return
+				}
+				gk := b.goalkeeper(player, team)
+				gk.Saves = ptr(b.value(tableGoalkeeper, s, "gk_saves"))
+				// Keepers who have faced no shots have a blank percentage and no series.
+				gk.SavePct = b.optional(tableGoalkeeper, s, "gk_save_pct")
+			})
+		}
+
+		// --- Team stats ---
+		if b.groups.teams && tbl.Find(th("team_name")).Length() > 0 && tbl.Find(td("team_points")).Length() > 0 {
+			// Newer standings carry xG columns; the "for" side has been named both
+			// xg_for and plain xg. Older seasons have neither and get no xG series.
+			xgField := "team_xg"
+			if tbl.Find(td("team_xg")).Length() == 0 {
+				xgField = "team_xg_alt"
+			}
+			b.rows(tbl).Each(func(i int, s *goquery.Selection) {
+				team := normalizeTeamName(s.Find(th("team_name")).Text())
+				if team == "" {
+					return
+				}
+				goalDiff, _ := b.parse(tableTeam, goalDiffReplacer.Replace(cellText(s, "team_goal_diff")))
+				// Fall back to the row's 1-based position in the standings when there is no rank cell.
+				rank, ok := b.parse(tableTeam, strings.TrimSpace(s.Find(th("team_rank")).Text()))
+				if !ok {
+					rank = float64(i + 1)
+				}
+				xg, xga := b.optional(tableTeam, s, xgField), b.optional(tableTeam, s, "team_xga")
+				// Only some competitions publish expected points; leave it out elsewhere.
+				xpoints := b.optional(tableTeam, s, "team_xpoints")
+				var form *float64
+				if v, ok := formPoints(cellText(s, "team_last_5")); ok {
+					form = ptr(v)
+				}
+				points, games := b.value(tableTeam, s, "team_points"), b.value(tableTeam, s, "team_games")
+				wins, against := b.value(tableTeam, s, "team_wins"), b.value(tableTeam, s, "team_goals_against")
+				ppg, winPct, gapg := 0.0, 0.0, 0.0
+				if games > 0 {
+					ppg, winPct, gapg = points/games, wins/games, against/games
+				}
+				b.stats.Teams = append(b.stats.Teams, TeamStat{
+					Team:                team,
+					Rank:                rank,
+					Points:              points,
+					Matches:             games,
+					PointsPerGame:       ppg,
+					WinPct:              winPct,
+					GoalsAgainstPerGame: gapg,
+					XPoints:             xpoints,
+					FormPoints:          form,
+					Wins:                wins,
+					Draws:               b.value(tableTeam, s, "team_draws"),
+					Losses:              b.value(tableTeam, s, "team_losses"),
+					GoalsFor:            b.value(tableTeam, s, "team_goals_for"),
+					GoalsAgainst:        against,
+					GoalDiff:            goalDiff,
+					XG:                  xg,
+					XGA:                 xga,
+				})
+			})
+		}
+
+		// --- Team expected goals against (advanced squad table, not the standings) ---
+		if b.groups.teams && tbl.Find(th("team_name")).Length() > 0 && tbl.Find(td("team_xga")).Length() > 0 &&
+			tbl.Find(td("team_points")).Length() == 0 {
+			b.rows(tbl).Each(func(_ int, s *goquery.Selection) {
+				if team := normalizeTeamName(s.Find(th("team_name")).Text()); team != "" {
+					b.teamExtra(team).xga = b.optional(tableTeam, s, "team_xga")
+				}
+			})
+		}
+
+		// --- Team home/away split (commented-out table below the standings) ---
+		// FBref has used both home_points and points_home for these columns.
+		homeField, awayField := "team_points_home", "team_points_away"
+		if tbl.Find(td("team_points_home_alt")).Length() > 0 {
+			homeField, awayField = "team_points_home_alt", "team_points_away_alt"
+		}
+		if b.groups.teams && tbl.Find(th("team_name")).Length() > 0 && tbl.Find(td(homeField)).Length() > 0 {
+			b.rows(tbl).Each(func(_ int, s *goquery.Selection) {
+				if team := normalizeTeamName(s.Find(th("team_name")).Text()); team != "" {
+					e := b.teamExtra(team)
+					e.pointsHome = ptr(b.value(tableTeam, s, homeField))
+					e.pointsAway = ptr(b.value(tableTeam, s, awayField))
+				}
+			})
+		}
+
+		// --- Team squad stats: possession, xA, squad size, average age ---
+		// The table is commented out and absent from some competitions' pages,
+		// in which case no series is set. Average age is blank early in the
+		// season and is skipped rather than written as 0. Every squad table
+		// (shooting, passing, ...) matches, but only the standard one has
+		// these columns, so each is set only from a table that has it.
+		if b.groups.teams && advancedTeamTable(tbl) {
+			b.stats.AdvancedTeamTable = true
+			b.rows(tbl).Each(func(_ int, s *goquery.Selection) {
+				team := normalizeTeamName(s.Find(th("team_name")).Text())
+				if team == "" {
+					return
+				}
+				e := b.teamExtra(team)
+				b.setOptional(&e.possession, tableTeam, s, "team_possession")
+				b.setOptional(&e.xa, tableTeam, s, "team_xa")
+				b.setOptional(&e.squadSize, tableTeam, s, "team_players_used")
+				b.setOptional(&e.avgAge, tableTeam, s, "team_avg_age")
+			})
+		}
+	}
+
+	// xGA from the standings wins over the squad table's; with neither, or
+	// without the home/away table, those series are left out.
+	for i := range b.stats.Teams {
+		t := &b.stats.Teams[i]
+		if e, ok := b.teamExtras[t.Team]; ok {
+			if t.XGA == nil {
+				t.XGA = e.xga
+			}
+			t.PointsHome, t.PointsAway = e.pointsHome, e.pointsAway
+			t.Possession, t.XA = e.possession, e.xa
+			t.SquadSize, t.AvgAge = e.squadSize, e.avgAge
+			t.CleanSheets = e.cleanSheets
+		}
+	}
+
+	b.stats.Players = dropRedundantCombined(b.stats.Players, func(p PlayerStat) (string, string) { return p.Player, p.Team })
+	b.stats.Goalkeepers = dropRedundantCombined(b.stats.Goalkeepers, func(g GoalkeeperStat) (string, string) { return g.Player, g.Team })
+
+	if len(b.stats.Players) == 0 && len(b.stats.Teams) == 0 && len(b.stats.Goalkeepers) == 0 {
+		return nil, errNoStats
+	}
+	return &b.stats, nil
+}
@@ -0,0 +1,403 @@
+package main
+
+import (
+	"maps"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// allGroups scrapes every metric group, as the default -collectors does.
+var allGroups = metricGroups{teams: true, players: true, goalkeepers: true}
+
+// mustDocs parses each HTML fragment into its own document.
+func mustDocs(t *testing.T, pages ...string) []*goquery.Document {
+	t.Helper()
+	var docs []*goquery.Document
+	for _, p := range pages {
+		d, err := goquery.NewDocumentFromReader(strings.NewReader(p))
+		if err != nil {
+			t.Fatalf("parsing fixture: %v", err)
+		}
+		docs = append(docs, d)
+	}
+	return docs
+}
+
+// playerTable wraps player rows in a table with the header cell the parser looks for.
+func playerTable(rows ...string) string {
+	return `<table class="stats_table"><thead><tr><th data-stat="player">Player</th></tr></thead><tbody>` +
+		strings.Join(rows, "") + `</tbody></table>`
+}
+
+func findPlayer(t *testing.T, stats *Stats, name, team string) PlayerStat {
+	t.Helper()
+	for _, p := range stats.Players {
+		if p.Player == name && p.Team == team {
+			return p
+		}
+	}
+	t.Fatalf("no player %s (%s) in %+v", name, team, stats.Players)
+	return PlayerStat{}
+}
+
+func checkValue(t *testing.T, field string, got *float64, want float64) {
+	t.Helper()
+	if got == nil {
+		t.Errorf("%s = nil, want %v", field, want)
+	} else if *got != want {
+		t.Errorf("%s = %v, want %v", field, *got, want)
+	}
+}
+
+func checkNil(t *testing.T, field string, got *float64) {
+	t.Helper()
+	if got != nil {
+		t.Errorf("%s = %v, want no value", field, *got)
+	}
+}
+
+// The shooting table has a goals column as well; it must not be read as the
+// standard table and overwrite what that table set.
+func TestParseStatsShootingDoesNotOverwriteStandard(t *testing.T) {
+	standard := playerTable(`<tr><td data-stat="player">Bukayo Saka</td><td data-stat="team">Arsenal</td>` +
+		`<td data-stat="goals">4</td><td data-stat="assists">3</td><td data-stat="cards_yellow">2</td>` +
+		`<td data-stat="minutes">654</td><td data-stat="games">8</td><td data-stat="xg">3.2</td><td data-stat="xg_assist">2.1</td></tr>`)
+	shooting := playerTable(`<tr><td data-stat="player">Bukayo Saka</td><td data-stat="team">Arsenal</td>` +
+		`<td data-stat="goals">4</td><td data-stat="shots">20</td><td data-stat="shots_on_target">9</td></tr>`)
+
+	stats, err := parseStats(mustDocs(t, standard, shooting), 0, allGroups)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := findPlayer(t, stats, "Bukayo Saka", "Arsenal")
+	checkValue(t, "goals", p.Goals, 4)
+	checkValue(t, "assists", p.Assists, 3)
+	checkValue(t, "yellow cards", p.YellowCards, 2)
+	checkValue(t, "minutes", p.Minutes, 654)
+	checkValue(t, "appearances", p.Appearances, 8)
+	checkValue(t, "xA", p.XA, 2.1)
+	checkValue(t, "shots", p.Shots, 20)
+}
+
+// The big-5 player page is one table of about 2,700 rows; the default row cap
+// must not cut it short.
+func TestParseStatsBig5FitsDefaultMaxRows(t *testing.T) {
+	const n = 2700
+	rows := make([]string, n)
+	for i := range rows {
+		rows[i] = `<tr><td data-stat="player">Player ` + strconv.Itoa(i) + `</td><td data-stat="team">Team</td>` +
+			`<td data-stat="comp_level">eng Premier League</td><td data-stat="goals">1</td><td data-stat="assists">0</td></tr>`
+	}
+	stats, err := parseStats(mustDocs(t, playerTable(rows...)), defaultMaxRows, allGroups)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stats.Players) != n {
+		t.Errorf("parsed %d players, want %d", len(stats.Players), n)
+	}
+}
+
+// A player who moved mid-season has a combined "2 Teams" row. It is dropped
+// when the table also splits the player by club, and kept as team "Multiple"
+// when it doesn't.
+func TestParseStatsCombinedTeamsRow(t *testing.T) {
+	row := func(player, team, goals string) string {
+		return `<tr><td data-stat="player">` + player + `</td><td data-stat="team">` + team + `</td>` +
+			`<td data-stat="goals">` + goals + `</td><td data-stat="assists">0</td></tr>`
+	}
+	tests := []struct {
+		name string
+		rows []string
+		want map[string]float64 // team → goals
+	}{
+		{
+			name: "with per-club rows",
+			rows: []string{row("Jadon Sancho", "2 Teams", "3"), row("Jadon Sancho", "Manchester Utd", "1"), row("Jadon Sancho", "Chelsea", "2")},
+			want: map[string]float64{"Manchester United": 1, "Chelsea": 2},
+		},
+		{
+			name: "combined row only",
+			rows: []string{row("Jadon Sancho", "2 Teams", "3")},
+			want: map[string]float64{multipleTeams: 3},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats, err := parseStats(mustDocs(t, playerTable(tt.rows...)), 0, allGroups)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := map[string]float64{}
+			for _, p := range stats.Players {
+				got[p.Team] = *p.Goals
+			}
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("got goals by team %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// formPoints reads the standings' "Last 5" cell, however FBref wraps each result.
+func TestFormPoints(t *testing.T) {
+	tests := []struct {
+		name string
+		cell string
+		want *float64
+	}{
+		{"linked", `<a href="/m/1">W</a> <a href="/m/2">W</a> <a href="/m/3">D</a> <a href="/m/4">L</a> <a href="/m/5">W</a>`, ptr(10)},
+		{"span-wrapped", `<div><span class="result"><a>D</a></span><span class="result"><a>L</a></span><span class="result"><a>W</a></span></div>`, ptr(4)},
+		{"more than five, oldest dropped", `<a>W</a><a>L</a><a>L</a><a>L</a><a>L</a><a>D</a>`, ptr(1)},
+		{"fewer than five", `<a>W</a>`, ptr(3)},
+		{"empty", ``, nil},
+		{"dash placeholder", `—`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page := `<table class="stats_table"><tbody><tr><th data-stat="team">Arsenal</th>` +
+				`<td data-stat="games">5</td><td data-stat="points">10</td><td data-stat="last_5">` + tt.cell + `</td></tr></tbody></table>`
+			stats, err := parseStats(mustDocs(t, page), 0, allGroups)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := stats.Teams[0].FormPoints
+			if tt.want == nil {
+				checkNil(t, "form points", got)
+			} else {
+				checkValue(t, "form points", got, *tt.want)
+			}
+		})
+	}
+}
+
+// Progressive carries come from the possession table and progressive passes
+// from the passing table; a player without a value in one gets no series.
+func TestParseStatsProgression(t *testing.T) {
+	possession := playerTable(
+		`<tr><td data-stat="player">Bukayo Saka</td><td data-stat="team">Arsenal</td><td data-stat="touches">412</td><td data-stat="progressive_carries">37</td></tr>`,
+		`<tr><td data-stat="player">David Raya</td><td data-stat="team">Arsenal</td><td data-stat="touches">301</td><td data-stat="progressive_carries"></td></tr>`,
+	)
+	passing := playerTable(
+		`<tr><td data-stat="player">Bukayo Saka</td><td data-stat="team">Arsenal</td><td data-stat="passes_pct">78.4</td><td data-stat="progressive_passes">41</td></tr>`,
+	)
+	stats, err := parseStats(mustDocs(t, possession, passing), 0, allGroups)
+	if err != nil {
+		t.Fatal(err)
+	}
+	saka := findPlayer(t, stats, "Bukayo Saka", "Arsenal")
+	checkValue(t, "progressive carries", saka.ProgCarries, 37)
+	checkValue(t, "progressive passes", saka.ProgPasses, 41)
+	checkValue(t, "pass completion", saka.PassPct, 78.4)
+	for _, p := range stats.Players {
+		if p.Player == "David Raya" {
+			checkNil(t, "progressive carries", p.ProgCarries)
+			checkNil(t, "progressive passes", p.ProgPasses)
+		}
+	}
+}
+
+// The same name precomposed and with combining accents, as FBref has served it.
+const (
+	nameNFC = "Jos\u00e9 S\u00e1"
+	nameNFD = "Jose\u0301 Sa\u0301"
+	teamNFC = "Deportivo Alav\u00e9s"
+	teamNFD = "Deportivo Alave\u0301s"
+)
+
+func TestSanitizeLabel(t *testing.T) {
+	tests := []struct {
+		name      string
+		normalize bool
+		in, want  string
+		team      bool
+	}{
+		{"off leaves decomposed alone", false, nameNFD, nameNFD, false},
+		{"off leaves surrounding space", false, " " + nameNFC + " ", " " + nameNFC + " ", false},
+		{"on composes", true, nameNFD, nameNFC, false},
+		{"on keeps precomposed", true, nameNFC, nameNFC, false},
+		{"on trims", true, "\t" + nameNFD + " ", nameNFC, false},
+		{"team off", false, teamNFD, teamNFD, true},
+		{"team on composes", true, teamNFD, teamNFC, true},
+		{"team on keeps precomposed", true, "  " + teamNFC, teamNFC, true},
+	}
+	defer func(old bool) { normalizeLabels = old }(normalizeLabels)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			normalizeLabels = tt.normalize
+			got := sanitizeLabel(tt.in)
+			if tt.team {
+				got = normalizeTeamName(tt.in)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// With -normalize-labels a player FBref spells with combining accents on one
+// page and precomposed on another is one player; without it they are two.
+func TestParseStatsNormalizesAcrossTables(t *testing.T) {
+	standard := playerTable(`<tr><td data-stat="player">` + nameNFC + `</td><td data-stat="team">Wolves</td>` +
+		`<td data-stat="goals">0</td><td data-stat="assists">0</td></tr>`)
+	shooting := playerTable(`<tr><td data-stat="player">` + nameNFD + `</td><td data-stat="team">Wolves</td><td data-stat="shots">2</td></tr>`)
+
+	defer func(old bool) { normalizeLabels = old }(normalizeLabels)
+	for _, normalize := range []bool{false, true} {
+		normalizeLabels = normalize
+		stats, err := parseStats(mustDocs(t, standard, shooting), 0, allGroups)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := 2
+		if normalize {
+			want = 1
+		}
+		if len(stats.Players) != want {
+			t.Errorf("normalize=%v: got %d players, want %d", normalize, len(stats.Players), want)
+		}
+		if normalize {
+			p := findPlayer(t, stats, nameNFC, "Wolverhampton Wanderers")
+			checkValue(t, "goals", p.Goals, 0)
+			checkValue(t, "shots", p.Shots, 2)
+		}
+	}
+}
+
+// matchweekOneTables are FBref's tables after the first round, one per
+// document as the page and its comments are parsed: counting columns filled
+// in (or blank for a team yet to play), decimal and advanced ones blank or
+// dashed.
+var matchweekOneTables = []string{
+	`<table class="stats_table"><tbody>
+<tr><th data-stat="rank">1</th><th data-stat="team">Arsenal</th><td data-stat="games">1</td><td data-stat="wins">1</td><td data-stat="draws">0</td><td data-stat="losses">0</td><td data-stat="goals_for">2</td><td data-stat="goals_against">0</td><td data-stat="goal_diff">+2</td><td data-stat="points">3</td><td data-stat="xg_for">—</td><td data-stat="xg_against"></td><td data-stat="xg_points">-</td><td data-stat="last_5"></td></tr>
+<tr><th data-stat="rank">2</th><th data-stat="team">Chelsea</th><td data-stat="games"></td><td data-stat="wins"></td><td data-stat="draws"></td><td data-stat="losses"></td><td data-stat="goals_for"></td><td data-stat="goals_against"></td><td data-stat="goal_diff"></td><td data-stat="points"></td><td data-stat="xg_for"></td><td data-stat="xg_against"></td><td data-stat="last_5"></td></tr>
+</tbody></table>`,
+	`<table class="stats_table" id="stats_squads_standard_for"><tbody>
+<tr><th data-stat="team">Arsenal</th><td data-stat="possession">–</td><td data-stat="xg_assist"></td><td data-stat="xg_against"></td><td data-stat="players_used">14</td><td data-stat="avg_age"></td></tr>
+</tbody></table>`,
+	`<table class="stats_table"><thead><tr><th data-stat="player">Player</th></tr></thead><tbody>
+<tr><td data-stat="player">Bukayo Saka</td><td data-stat="team">Arsenal</td><td data-stat="age">-</td><td data-stat="goals">1</td><td data-stat="assists"></td><td data-stat="minutes">90</td><td data-stat="xg">0.4</td><td data-stat="xg_assist"></td><td data-stat="goals_per90"></td><td data-stat="npxg">—</td></tr>
+</tbody></table>`,
+	`<table class="stats_table"><thead><tr><th data-stat="player">Player</th></tr></thead><tbody>
+<tr><td data-stat="player">Bukayo Saka</td><td data-stat="team">Arsenal</td><td data-stat="passes_pct"></td><td data-stat="assisted_shots">-</td></tr>
+</tbody></table>`,
+	`<table class="stats_table"><thead><tr><th data-stat="player">Player</th></tr></thead><tbody>
+<tr><td data-stat="player">David Raya</td><td data-stat="team">Arsenal</td><td data-stat="clean_sheets">1</td><td data-stat="gk_saves"></td><td data-stat="gk_save_pct"></td></tr>
+</tbody></table>`,
+}
+
+func TestParseStatsMatchweekOne(t *testing.T) {
+	stats, err := parseStats(mustDocs(t, matchweekOneTables...), 0, allGroups)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stats.Teams) != 2 {
+		t.Fatalf("got %d teams, want 2", len(stats.Teams))
+	}
+	if n := stats.ParseErrors[tableTeam] + stats.ParseErrors[tablePlayer] + stats.ParseErrors[tableGoalkeeper]; n != 0 {
+		t.Errorf("blank and dashed cells counted as %d parse errors", n)
+	}
+
+	arsenal, chelsea := stats.Teams[0], stats.Teams[1]
+	if arsenal.Points != 3 || arsenal.Matches != 1 || arsenal.GoalDiff != 2 {
+		t.Errorf("Arsenal counting columns = %+v", arsenal)
+	}
+	checkNil(t, "Arsenal xG", arsenal.XG)
+	checkNil(t, "Arsenal xGA", arsenal.XGA)
+	checkNil(t, "Arsenal xPoints", arsenal.XPoints)
+	checkNil(t, "Arsenal form", arsenal.FormPoints)
+	checkNil(t, "Arsenal possession", arsenal.Possession)
+	checkNil(t, "Arsenal xA", arsenal.XA)
+	checkNil(t, "Arsenal avg age", arsenal.AvgAge)
+	checkValue(t, "Arsenal squad size", arsenal.SquadSize, 14)
+	if chelsea.Points != 0 || chelsea.Matches != 0 || chelsea.Wins != 0 {
+		t.Errorf("Chelsea's blank counting columns should read as 0, got %+v", chelsea)
+	}
+	checkNil(t, "Chelsea xG", chelsea.XG)
+
+	saka := findPlayer(t, stats, "Bukayo Saka", "Arsenal")
+	checkValue(t, "goals", saka.Goals, 1)
+	checkValue(t, "assists", saka.Assists, 0)
+	checkValue(t, "xG", saka.XG, 0.4)
+	checkNil(t, "xA", saka.XA)
+	checkValue(t, "age", saka.AgeYears, 0)
+	checkNil(t, "goals per 90", saka.GoalsPer90)
+	checkNil(t, "npxG", saka.NPXG)
+	checkNil(t, "pass completion", saka.PassPct)
+	checkNil(t, "key passes", saka.KeyPasses)
+
+	if len(stats.Goalkeepers) != 1 {
+		t.Fatalf("got %d goalkeepers, want 1", len(stats.Goalkeepers))
+	}
+	raya := stats.Goalkeepers[0]
+	checkValue(t, "clean sheets", raya.CleanSheets, 1)
+	checkValue(t, "saves", raya.Saves, 0)
+	checkNil(t, "save %", raya.SavePct)
+}
+
+// The live page carries the standings and the squad table side by side; each
+// table is matched on its own, so the squad rows are not read as extra teams.
+func TestParseStatsStandingsAndSquadTableOnOnePage(t *testing.T) {
+	page := `<html><body>
+<table class="stats_table" id="results_overall"><tbody>
+<tr><th data-stat="team">Arsenal</th><td data-stat="games">8</td><td data-stat="points">19</td></tr>
+<tr><th data-stat="team">Chelsea</th><td data-stat="games">8</td><td data-stat="points">14</td></tr>
+</tbody></table>
+<table class="stats_table" id="stats_squads_standard_for"><tbody>
+<tr><th data-stat="team">Arsenal</th><td data-stat="players_used">22</td><td data-stat="possession">58.1</td></tr>
+<tr><th data-stat="team">Chelsea</th><td data-stat="players_used">25</td><td data-stat="possession">55.4</td></tr>
+</tbody></table>
+</body></html>`
+	stats, err := parseStats(mustDocs(t, page), 0, allGroups)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stats.Teams) != 2 {
+		t.Fatalf("got %d teams, want 2: %+v", len(stats.Teams), stats.Teams)
+	}
+	if !stats.AdvancedTeamTable {
+		t.Error("squad table not detected")
+	}
+	arsenal, chelsea := stats.Teams[0], stats.Teams[1]
+	if arsenal.Points != 19 || chelsea.Points != 14 {
+		t.Errorf("points = %v, %v, want 19, 14", arsenal.Points, chelsea.Points)
+	}
+	if chelsea.Rank != 2 {
+		t.Errorf("Chelsea rank = %v, want its row in the standings (2)", chelsea.Rank)
+	}
+	checkValue(t, "Arsenal possession", arsenal.Possession, 58.1)
+	checkValue(t, "Chelsea squad size", chelsea.SquadSize, 25)
+}
+
+// Every squad table matches the squad branch, but only the standard one has
+// possession, xA and average age; a later table must not clear them. Likewise
+// only the shooting table has npxG per shot.
+func TestParseStatsLaterTableKeepsColumnsItLacks(t *testing.T) {
+	standings := `<table class="stats_table"><tbody><tr><th data-stat="team">Arsenal</th><td data-stat="games">8</td><td data-stat="points">19</td></tr></tbody></table>`
+	squadStandard := `<table class="stats_table" id="stats_squads_standard_for"><tbody><tr><th data-stat="team">Arsenal</th>` +
+		`<td data-stat="players_used">22</td><td data-stat="possession">58.1</td><td data-stat="xg_assist">9.3</td><td data-stat="avg_age">26.1</td></tr></tbody></table>`
+	squadShooting := `<table class="stats_table" id="stats_squads_shooting_for"><tbody><tr><th data-stat="team">Arsenal</th>` +
+		`<td data-stat="players_used">22</td><td data-stat="shots">130</td></tr></tbody></table>`
+	shooting := playerTable(`<tr><td data-stat="player">Bukayo Saka</td><td data-stat="team">Arsenal</td>` +
+		`<td data-stat="shots">20</td><td data-stat="npxg">2.9</td><td data-stat="npxg_per_shot">0.15</td></tr>`)
+	standard := playerTable(`<tr><td data-stat="player">Bukayo Saka</td><td data-stat="team">Arsenal</td>` +
+		`<td data-stat="goals">4</td><td data-stat="assists">3</td><td data-stat="npxg">2.9</td></tr>`)
+
+	stats, err := parseStats(mustDocs(t, standings, squadStandard, squadShooting, shooting, standard), 0, allGroups)
+	if err != nil {
+		t.Fatal(err)
+	}
+	arsenal := stats.Teams[0]
+	checkValue(t, "possession", arsenal.Possession, 58.1)
+	checkValue(t, "xA", arsenal.XA, 9.3)
+	checkValue(t, "avg age", arsenal.AvgAge, 26.1)
+	checkValue(t, "squad size", arsenal.SquadSize, 22)
+
+	saka := findPlayer(t, stats, "Bukayo Saka", "Arsenal")
+	checkValue(t, "npxG", saka.NPXG, 2.9)
+	checkValue(t, "npxG per shot", saka.NPXGPerShot, 0.15)
+}
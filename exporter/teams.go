@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// --------------------- Team Names ---------------------
+
+// teamAliases maps the abbreviated or variant spellings FBref uses in some tables
+// to a single canonical club name, so each club ends up with one series.
+var teamAliases = map[string]string{
+	"Brighton":        "Brighton & Hove Albion",
+	"Manchester Utd":  "Manchester United",
+	"Newcastle Utd":   "Newcastle United",
+	"Nott'ham Forest": "Nottingham Forest",
+	"Sheffield Utd":   "Sheffield United",
+	"Tottenham":       "Tottenham Hotspur",
+	"West Brom":       "West Bromwich Albion",
+	"West Ham":        "West Ham United",
+	"Wolves":          "Wolverhampton Wanderers",
+}
+
+// normalizeTeamName trims and collapses whitespace in a team name and resolves
+// known aliases to their canonical spelling.
+func normalizeTeamName(name string) string {
+	name = strings.Join(strings.Fields(sanitizeLabel(name)), " ")
+	if canonical, ok := teamAliases[name]; ok {
+		return canonical
+	}
+	return name
+}
+
+// loadTeamAliases merges a JSON object of alias → canonical name from path into
+// the built-in aliases, letting users tracking other leagues add their own.
+func loadTeamAliases(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading team aliases: %w", err)
+	}
+	var aliases map[string]string
+	if err := json.Unmarshal(data, &aliases); err != nil {
+		return fmt.Errorf("parsing team aliases %s: %w", path, err)
+	}
+	for alias, canonical := range aliases {
+		teamAliases[strings.Join(strings.Fields(alias), " ")] = canonical
+	}
+	return nil
+}
+
+// teamShortNames maps canonical club names to the three-letter codes used for
+// the short_name label.
+var teamShortNames = map[string]string{
+	"Arsenal":                 "ARS",
+	"Aston Villa":             "AVL",
+	"Bournemouth":             "BOU",
+	"Brentford":               "BRE",
+	"Brighton & Hove Albion":  "BHA",
+	"Burnley":                 "BUR",
+	"Chelsea":                 "CHE",
+	"Crystal Palace":          "CRY",
+	"Everton":                 "EVE",
+	"Fulham":                  "FUL",
+	"Ipswich Town":            "IPS",
+	"Leeds United":            "LEE",
+	"Leicester City":          "LEI",
+	"Liverpool":               "LIV",
+	"Luton Town":              "LUT",
+	"Manchester City":         "MCI",
+	"Manchester United":       "MUN",
+	"Newcastle United":        "NEW",
+	"Norwich City":            "NOR",
+	"Nottingham Forest":       "NFO",
+	"Sheffield United":        "SHU",
+	"Southampton":             "SOU",
+	"Sunderland":              "SUN",
+	"Tottenham Hotspur":       "TOT",
+	"Watford":                 "WAT",
+	"West Bromwich Albion":    "WBA",
+	"West Ham United":         "WHU",
+	"Wolverhampton Wanderers": "WOL",
+}
+
+// shortTeamName returns the short_name for a canonical team name. Clubs without
+// a code get the first three letters of their name, upper-cased.
+func shortTeamName(team string) string {
+	if code, ok := teamShortNames[team]; ok {
+		return code
+	}
+	var code []rune
+	for _, r := range team {
+		if unicode.IsLetter(r) {
+			code = append(code, unicode.ToUpper(r))
+			if len(code) == 3 {
+				break
+			}
+		}
+	}
+	return string(code)
+}
+
+// loadTeamShortNames merges a JSON object of canonical name → short name from
+// path into the built-in codes.
+func loadTeamShortNames(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading team short names: %w", err)
+	}
+	var names map[string]string
+	if err := json.Unmarshal(data, &names); err != nil {
+		return fmt.Errorf("parsing team short names %s: %w", path, err)
+	}
+	for team, short := range names {
+		teamShortNames[strings.Join(strings.Fields(team), " ")] = short
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"os"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+)
+
+// --------------------- Textfile Output ---------------------
+
+// writeTextfile scrapes once and writes every metric in reg to path in the
+// Prometheus text format, for node_exporter's textfile collector run from
+// cron, or to stdout when path is "-". The file is replaced atomically so the
+// collector never reads half of it. It returns the process exit code: 1 when
+// the scrape or the write failed. The metrics are written even after a failed
+// scrape, so fbref_scrape_success shows the failure.
+func (s *scraper) writeTextfile(ctx context.Context, reg prometheus.Gatherer, path string) int {
+	code := 0
+	if err := s.runScrape(ctx); err != nil {
+		code = 1
+	}
+	families, err := reg.Gather()
+	if err != nil {
+		log.Printf("[ERROR] Gathering metrics: %v", err)
+		return 1
+	}
+	var buf bytes.Buffer
+	enc := expfmt.NewEncoder(&buf, expfmt.NewFormat(expfmt.TypeTextPlain))
+	for _, mf := range families {
+		if err := enc.Encode(mf); err != nil {
+			log.Printf("[ERROR] Encoding %s: %v", mf.GetName(), err)
+			return 1
+		}
+	}
+	if path == "-" {
+		if _, err := os.Stdout.Write(buf.Bytes()); err != nil {
+			return 1
+		}
+		return code
+	}
+	if err := writeFileAtomic(path, buf.Bytes()); err != nil {
+		log.Printf("[ERROR] Writing %s: %v", path, err)
+		return 1
+	}
+	log.Printf("[INFO] Wrote %d metric families to %s", len(families), path)
+	return code
+}